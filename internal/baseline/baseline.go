@@ -0,0 +1,155 @@
+// Package baseline recognizes soft-404 / reflective responses: pages that
+// return 200 (or any fixed status) for every path under a directory but
+// differ byte-for-byte because they embed the requested word itself —
+// timestamps, CSRF tokens, or an echo of the URL. Exact content hashing
+// (sha1 of the body) misses these; a fingerprint built from a SimHash of
+// the body with the probe word redacted catches them instead.
+package baseline
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Fingerprint summarizes a response closely enough to recognize "the same
+// templated page regardless of path" without requiring an exact content
+// match.
+type Fingerprint struct {
+	Status      int
+	Length      int
+	SimHash     uint64
+	HeaderNames []string
+}
+
+var whitespaceRe = regexp.MustCompile(`\s+`)
+
+// Compute builds a Fingerprint for one response. probeWord, if non-empty,
+// is redacted from the body before shingling so the fingerprint reflects
+// the page's template rather than the one token that varies by
+// construction.
+func Compute(status int, body []byte, headers http.Header, probeWord string) Fingerprint {
+	text := string(body)
+	if probeWord != "" {
+		text = strings.ReplaceAll(text, probeWord, "")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, strings.ToLower(k))
+	}
+	sort.Strings(names)
+	return Fingerprint{
+		Status:      status,
+		Length:      len(text),
+		SimHash:     simHash(text),
+		HeaderNames: names,
+	}
+}
+
+// shingles splits text into whitespace-delimited tokens and returns every
+// contiguous run of three of them. Text with fewer than three tokens is
+// returned as its tokens.
+func shingles(text string) []string {
+	tokens := whitespaceRe.Split(strings.TrimSpace(text), -1)
+	if len(tokens) < 3 {
+		return tokens
+	}
+	out := make([]string, 0, len(tokens)-2)
+	for i := 0; i+3 <= len(tokens); i++ {
+		out = append(out, strings.Join(tokens[i:i+3], " "))
+	}
+	return out
+}
+
+// simHash is the standard 64-bit Charikar construction: each shingle hashes
+// to 64 bits that vote +1/-1 into a per-bit counter (weight 1 per
+// shingle), and the final hash has bit i set wherever its counter landed
+// positive.
+func simHash(text string) uint64 {
+	var counters [64]int
+	for _, sh := range shingles(text) {
+		h := fnv64a(sh)
+		for i := 0; i < 64; i++ {
+			if h&(1<<uint(i)) != 0 {
+				counters[i]++
+			} else {
+				counters[i]--
+			}
+		}
+	}
+	var out uint64
+	for i := 0; i < 64; i++ {
+		if counters[i] > 0 {
+			out |= 1 << uint(i)
+		}
+	}
+	return out
+}
+
+func fnv64a(s string) uint64 {
+	const offset64 = 14695981039346656037
+	const prime64 = 1099511628211
+	h := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime64
+	}
+	return h
+}
+
+// hamming returns the number of differing bits between two 64-bit hashes.
+func hamming(a, b uint64) int {
+	x := a ^ b
+	count := 0
+	for x != 0 {
+		count++
+		x &= x - 1
+	}
+	return count
+}
+
+// sameHeaderNames reports whether two sorted header-name slices are equal.
+func sameHeaderNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// withinPercent reports whether b is within pct percent of a (or both are
+// zero).
+func withinPercent(a, b, pct int) bool {
+	if a == 0 && b == 0 {
+		return true
+	}
+	if a == 0 {
+		return false
+	}
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*100 <= a*pct
+}
+
+// SimilarTo reports whether candidate looks like the same soft-404 page as
+// f: matching status, matching header-name set, content length within ±5%,
+// and a SimHash within maxDistance bits.
+func (f Fingerprint) SimilarTo(candidate Fingerprint, maxDistance int) bool {
+	if f.Status != candidate.Status {
+		return false
+	}
+	if !sameHeaderNames(f.HeaderNames, candidate.HeaderNames) {
+		return false
+	}
+	if !withinPercent(f.Length, candidate.Length, 5) {
+		return false
+	}
+	return hamming(f.SimHash, candidate.SimHash) <= maxDistance
+}
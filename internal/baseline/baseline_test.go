@@ -0,0 +1,77 @@
+package baseline
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSimilarToMatchesNearIdenticalReflectiveBodies(t *testing.T) {
+	headers := http.Header{"Content-Type": []string{"text/html"}}
+	base := Compute(200, []byte("Hello probeword, welcome to the page you requested."), headers, "probeword")
+	candidate := Compute(200, []byte("Hello otherword, welcome to the page you requested."), headers, "otherword")
+
+	if !base.SimilarTo(candidate, 3) {
+		t.Fatalf("expected templated pages differing only by the redacted probe word to be similar")
+	}
+}
+
+func TestSimilarToRejectsDifferentStatus(t *testing.T) {
+	headers := http.Header{}
+	base := Compute(200, []byte("same body"), headers, "")
+	candidate := Compute(404, []byte("same body"), headers, "")
+
+	if base.SimilarTo(candidate, 64) {
+		t.Fatalf("fingerprints with different status codes must never be similar")
+	}
+}
+
+func TestSimilarToRejectsDifferentHeaderNames(t *testing.T) {
+	base := Compute(200, []byte("same body"), http.Header{"X-Foo": []string{"1"}}, "")
+	candidate := Compute(200, []byte("same body"), http.Header{"X-Bar": []string{"1"}}, "")
+
+	if base.SimilarTo(candidate, 64) {
+		t.Fatalf("fingerprints with different header-name sets must never be similar")
+	}
+}
+
+func TestSimilarToRejectsDivergentContent(t *testing.T) {
+	headers := http.Header{}
+	base := Compute(200, []byte("the quick brown fox jumps over the lazy dog repeatedly every single day"), headers, "")
+	candidate := Compute(200, []byte("completely unrelated content about something else entirely different"), headers, "")
+
+	if base.SimilarTo(candidate, 3) {
+		t.Fatalf("unrelated bodies should not be within a tight Hamming distance")
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	cases := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xFF, 0x00, 8},
+		{0xFFFFFFFFFFFFFFFF, 0, 64},
+	}
+	for _, c := range cases {
+		if got := hamming(c.a, c.b); got != c.want {
+			t.Errorf("hamming(%x, %x) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestSimHashStableForIdenticalText(t *testing.T) {
+	text := "alpha beta gamma delta epsilon zeta"
+	if simHash(text) != simHash(text) {
+		t.Fatalf("simHash must be deterministic for identical input")
+	}
+}
+
+func TestSimHashWithinDistanceForMinorEdit(t *testing.T) {
+	a := simHash("the rain in spain falls mainly on the plain today")
+	b := simHash("the rain in spain falls mainly on the field today")
+	if d := hamming(a, b); d > 10 {
+		t.Errorf("expected a single-word edit to stay within a small Hamming distance, got %d", d)
+	}
+}
@@ -0,0 +1,36 @@
+package crawler
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Resume reads a checkpoint previously written by a Crawl call with
+// Options.CheckpointPath set, so the caller can re-run Crawl against the
+// same StartURL and have it rehydrate visited/results/queue instead of
+// starting over.
+func Resume(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// writeCheckpoint atomically persists cp to path (write to a temp file, then
+// rename) so a crash mid-write can't corrupt the last good checkpoint.
+func writeCheckpoint(path string, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
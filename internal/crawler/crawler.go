@@ -4,14 +4,180 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
 )
 
+// Options bundles the opt-in behaviors Crawl supports beyond the basic
+// same-host regex crawl. The zero value reproduces the original behavior.
+type Options struct {
+	Debug bool
+
+	// SeedRobots fetches /robots.txt and seeds its Disallow entries; Allow
+	// entries are used only to decide which paths are disallowed.
+	SeedRobots bool
+	// SeedSitemap fetches the sitemaps robots.txt lists (read only when
+	// SeedRobots is also set) or, when it lists none, /sitemap.xml, following
+	// nested sitemap indexes and .xml.gz, and seeds their URLs.
+	SeedSitemap bool
+	// Disallow controls whether robots.txt Disallow paths are skipped or
+	// enqueued anyway for coverage. Only consulted when SeedRobots is set.
+	Disallow DisallowPolicy
+
+	// RenderJS records whether the caller chose the headless-Chrome path.
+	// Neither Crawl nor CrawlRendered reads it: rendering happens by calling
+	// CrawlRendered, whether or not this is set.
+	RenderJS bool
+	// RenderConcurrency caps how many browser tabs CrawlRendered runs at
+	// once. Browser tabs are far more expensive than HTTP workers, so this
+	// is tracked separately from the crawl's own goroutine count. Defaults
+	// to 4 when zero.
+	RenderConcurrency int
+
+	// Scope controls which hosts/URLs are in bounds for this crawl. The
+	// zero value restricts to the exact start host, matching the original
+	// behavior before Scope existed.
+	Scope Scope
+
+	// UserAgent overrides the default Go HTTP client user agent.
+	UserAgent string
+	// GlobalRPS caps total requests/sec across every host. Zero means unlimited.
+	GlobalRPS float64
+	// PerHostRPS caps requests/sec to any single host. Zero means unlimited.
+	PerHostRPS float64
+	// HonorCrawlDelay fetches /robots.txt (independent of SeedRobots) and,
+	// when it specifies a Crawl-delay, uses it in place of PerHostRPS for
+	// that host.
+	HonorCrawlDelay bool
+
+	// CheckpointPath, when set, periodically persists visited/results/queue
+	// state to this path as JSON so a crashed or interrupted crawl can
+	// resume with Resume instead of starting over.
+	CheckpointPath string
+	// CheckpointInterval controls how often the checkpoint is written.
+	// Defaults to 10s when zero.
+	CheckpointInterval time.Duration
+
+	// KeepJSLinks lets .js URLs survive the static-extension filter that
+	// would otherwise drop them before they ever reach Crawl's results.
+	// Set this when the caller wants to mine discovered JS files (e.g.
+	// wordgen.FromJSFiles); it has no other effect on crawl behavior.
+	KeepJSLinks bool
+}
+
+// QueueItem is one pending crawl job: a URL discovered at a given depth.
+type QueueItem struct {
+	URL   string `json:"url"`
+	Depth int    `json:"depth"`
+}
+
+// Checkpoint is the serializable snapshot Crawl periodically writes to
+// Options.CheckpointPath and Resume reads back to continue a crawl.
+type Checkpoint struct {
+	StartURL string      `json:"start_url"`
+	Visited  []string    `json:"visited"`
+	Results  []string    `json:"results"`
+	Queue    []QueueItem `json:"queue"`
+}
+
+// urlPatterns are the case-insensitive link-extraction patterns shared by the
+// regex crawler and the rendered-DOM extractor.
+var urlPatterns = []string{
+	`(?i)href\s*=\s*["']([^"']+)["']`,
+	`(?i)href\s*=\s*([^\s"'>]+)`,
+	`(?i)src\s*=\s*["']([^"']+)["']`,
+	`(?i)src\s*=\s*([^\s"'>]+)`,
+	`(?i)action\s*=\s*["']([^"']+)["']`,
+	`(?i)action\s*=\s*([^\s"'>]+)`,
+	`(?i)(?:fetch|XMLHttpRequest|ajax)\s*\(\s*["']([^"']+)["']`,
+	`(?i)<a[^>]+href\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<link[^>]+href\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<script[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<img[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<iframe[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<form[^>]+action\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<object[^>]+data\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<embed[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<source[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)<param[^>]+value\s*=\s*["']([^"']+)["'][^>]*>`,
+	`(?i)https?://[^\s"'<>]+`,
+}
+
+var linkRegexes = func() []*regexp.Regexp {
+	out := make([]*regexp.Regexp, 0, len(urlPatterns))
+	for _, p := range urlPatterns { out = append(out, regexp.MustCompile(p)) }
+	return out
+}()
+
+// skipStaticExt reports whether p looks like a static asset not worth
+// crawling further. keepJS lets .js URLs through anyway - set when the
+// caller wants to mine them (e.g. wordgen.FromJSFiles), since otherwise no
+// .js URL would ever survive into Crawl's results.
+func skipStaticExt(p string, keepJS bool) bool {
+	p = strings.ToLower(p)
+	if keepJS && strings.HasSuffix(p, ".js") {
+		return false
+	}
+	for _, ext := range []string{ ".css", ".js", ".png", ".jpg", ".jpeg", ".gif", ".ico", ".svg", ".woff", ".woff2", ".ttf", ".eot" } {
+		if strings.HasSuffix(p, ext) { return true }
+	}
+	return false
+}
+
+// normalizeFragment strips the fragment from u and returns its string form.
+func normalizeFragment(u *url.URL) string {
+	u.Fragment = ""
+	return u.String()
+}
+
+// resolveLink applies the same scope/robots/static-extension policy the
+// plain regex crawl's resolve closure does: it resolves raw against page,
+// then rejects anything out of scope, disallowed by robots.txt (when
+// disallow is DisallowSkip), or pointing at a static asset. Returns "" if
+// the candidate should be dropped. Shared by Crawl and extractLinksFromHTML
+// so the rendered-DOM path can't drift from the regex path's policy.
+func resolveLink(raw string, page *url.URL, baseHost string, scope Scope, robots *robotsRules, disallow DisallowPolicy, keepJS bool) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" { return "" }
+	if strings.HasPrefix(raw, "javascript:") || strings.HasPrefix(raw, "data:") || strings.HasPrefix(raw, "#") {
+		return ""
+	}
+	rel, err := url.Parse(raw)
+	if err != nil { return "" }
+	abs := page.ResolveReference(rel)
+	if !scope.hostInScope(abs.Host, baseHost) { return "" }
+	if abs.Scheme != "http" && abs.Scheme != "https" { return "" }
+	if skipStaticExt(abs.Path, keepJS) { return "" }
+	if robots != nil && disallow == DisallowSkip && !robots.allows(abs.Path) { return "" }
+	full := normalizeFragment(abs)
+	if !scope.allowsURL(full) { return "" }
+	return full
+}
+
+// extractLinksFromHTML runs the shared link regexes over a rendered page's
+// HTML, resolving each candidate against pageURLStr through the same
+// scope/robots policy the regex crawl applies via resolveLink.
+func extractLinksFromHTML(html, pageURLStr string, baseHost string, scope Scope, robots *robotsRules, disallow DisallowPolicy, keepJS bool) []string {
+	page, err := url.Parse(pageURLStr)
+	if err != nil { return nil }
+	var out []string
+	for _, re := range linkRegexes {
+		for _, m := range re.FindAllStringSubmatch(html, -1) {
+			candidate := ""
+			if len(m) >= 2 { candidate = m[1] } else if len(m) == 1 { candidate = m[0] }
+			full := resolveLink(candidate, page, baseHost, scope, robots, disallow, keepJS)
+			if full == "" { continue }
+			out = append(out, full)
+		}
+	}
+	return out
+}
+
 // Crawl discovers same-domain URLs up to maxDepth and maxPages
-func Crawl(startURL string, maxDepth int, maxPages int) ([]string, error) {
+func Crawl(startURL string, maxDepth int, maxPages int, opts Options) ([]string, error) {
 	if maxDepth <= 0 { maxDepth = 1 }
 	if maxPages <= 0 { maxPages = 1000 }
 
@@ -30,73 +196,103 @@ func Crawl(startURL string, maxDepth int, maxPages int) ([]string, error) {
 		},
 	}
 
-	type item struct{ u string; d int }
-	jobs := make(chan item, 20000)
+	jobs := make(chan QueueItem, 20000)
 	visited := make(map[string]bool)
 	results := make(map[string]bool)
 	var mu sync.Mutex
 	pending := &sync.WaitGroup{}
 	wg := &sync.WaitGroup{}
 
-	// Case-insensitive patterns with quoted and unquoted attributes, plus raw URLs in text/JS
-	urlPatterns := []string{
-		`(?i)href\s*=\s*["']([^"']+)["']`,
-		`(?i)href\s*=\s*([^\s"'>]+)`,
-		`(?i)src\s*=\s*["']([^"']+)["']`,
-		`(?i)src\s*=\s*([^\s"'>]+)`,
-		`(?i)action\s*=\s*["']([^"']+)["']`,
-		`(?i)action\s*=\s*([^\s"'>]+)`,
-		`(?i)(?:fetch|XMLHttpRequest|ajax)\s*\(\s*["']([^"']+)["']`,
-		`(?i)<a[^>]+href\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<link[^>]+href\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<script[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<img[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<iframe[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<form[^>]+action\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<object[^>]+data\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<embed[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<source[^>]+src\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)<param[^>]+value\s*=\s*["']([^"']+)["'][^>]*>`,
-		`(?i)https?://[^\s"'<>]+`,
-	}
-	regexes := make([]*regexp.Regexp, 0, len(urlPatterns))
-	for _, p := range urlPatterns { regexes = append(regexes, regexp.MustCompile(p)) }
-
-	skipExt := func(p string) bool {
-		p = strings.ToLower(p)
-		for _, ext := range []string{ ".css", ".js", ".png", ".jpg", ".jpeg", ".gif", ".ico", ".svg", ".woff", ".woff2", ".ttf", ".eot" } {
-			if strings.HasSuffix(p, ext) { return true }
+	limiter := newPoliteLimiter(opts.GlobalRPS, opts.PerHostRPS)
+	var robotsDelayMu sync.Mutex
+	robotsDelayByHost := make(map[string]float64)
+	crawlDelayFor := func(host string) float64 {
+		if !opts.HonorCrawlDelay {
+			return 0
 		}
-		return false
+		robotsDelayMu.Lock()
+		defer robotsDelayMu.Unlock()
+		if d, ok := robotsDelayByHost[host]; ok {
+			return d
+		}
+		hostURL := &url.URL{Scheme: start.Scheme, Host: host}
+		r, err := fetchRobots(client, hostURL)
+		d := 0.0
+		if err == nil {
+			d = r.crawlDelay
+		}
+		robotsDelayByHost[host] = d
+		return d
 	}
 
-	normalize := func(u *url.URL) string {
-		// strip fragments
-		u.Fragment = ""
-		return u.String()
-	}
+	regexes := linkRegexes
+
+	var robots *robotsRules
+	scope := opts.Scope
+	hostCounts := make(map[string]int)
 
 	resolve := func(raw string, page *url.URL) string {
-		raw = strings.TrimSpace(raw)
-		if raw == "" { return "" }
-		if strings.HasPrefix(raw, "javascript:") || strings.HasPrefix(raw, "data:") || strings.HasPrefix(raw, "#") {
-			return ""
-		}
-		rel, err := url.Parse(raw)
-		if err != nil { return "" }
-		abs := page.ResolveReference(rel)
-		if abs.Host != baseHost { return "" }
-		if abs.Scheme != "http" && abs.Scheme != "https" { return "" }
-		if skipExt(abs.Path) { return "" }
-		return normalize(abs)
+		return resolveLink(raw, page, baseHost, scope, robots, opts.Disallow, opts.KeepJSLinks)
+	}
+
+	// canEnqueue applies the per-host page budget on top of the global
+	// maxPages cap already checked at each call site.
+	canEnqueue := func(fullURL string) bool {
+		u, err := url.Parse(fullURL)
+		if err != nil { return true }
+		budget := scope.pageBudget(u.Host, maxPages)
+		if hostCounts[u.Host] >= budget { return false }
+		hostCounts[u.Host]++
+		return true
+	}
+
+	// queued tracks jobs that have been pushed onto the channel but not yet
+	// picked up by a worker, purely so checkpoints can capture an accurate
+	// pending-queue snapshot (the channel itself can't be peeked).
+	var queuedMu sync.Mutex
+	queued := make(map[string]QueueItem)
+	enqueueJob := func(it QueueItem) {
+		queuedMu.Lock()
+		queued[it.URL] = it
+		queuedMu.Unlock()
+		pending.Add(1)
+		jobs <- it
+	}
+	dequeueJob := func(it QueueItem) {
+		queuedMu.Lock()
+		delete(queued, it.URL)
+		queuedMu.Unlock()
+	}
+
+	if opts.CheckpointPath != "" {
+		interval := opts.CheckpointInterval
+		if interval <= 0 { interval = 10 * time.Second }
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		go func() {
+			for range ticker.C {
+				mu.Lock()
+				v := make([]string, 0, len(visited))
+				for u := range visited { v = append(v, u) }
+				r := make([]string, 0, len(results))
+				for u := range results { r = append(r, u) }
+				mu.Unlock()
+				queuedMu.Lock()
+				q := make([]QueueItem, 0, len(queued))
+				for _, it := range queued { q = append(q, it) }
+				queuedMu.Unlock()
+				_ = writeCheckpoint(opts.CheckpointPath, Checkpoint{StartURL: startURL, Visited: v, Results: r, Queue: q})
+			}
+		}()
 	}
 
 	worker := func() {
 		defer wg.Done()
 		for it := range jobs {
 			pending.Done()
-			if it.d > maxDepth { continue }
-			pageURL := it.u
+			dequeueJob(it)
+			if it.Depth > maxDepth { continue }
+			pageURL := it.URL
 
 			mu.Lock()
 			if visited[pageURL] { mu.Unlock(); continue }
@@ -106,6 +302,8 @@ func Crawl(startURL string, maxDepth int, maxPages int) ([]string, error) {
 
 			req, err := http.NewRequest(http.MethodGet, pageURL, nil)
 			if err != nil { continue }
+			if opts.UserAgent != "" { req.Header.Set("User-Agent", opts.UserAgent) }
+			limiter.wait(req.URL.Host, crawlDelayFor(req.URL.Host))
 			resp, err := client.Do(req)
 			if err != nil { continue }
 			status := resp.StatusCode
@@ -125,22 +323,29 @@ func Crawl(startURL string, maxDepth int, maxPages int) ([]string, error) {
 
 			page, _ := url.Parse(pageURL)
 			body := builder.String()
+			enqueue := func(candidate string) {
+				abs := resolve(candidate, page)
+				if abs == "" { return }
+				mu.Lock()
+				if !results[abs] && len(results) < maxPages && canEnqueue(abs) {
+					results[abs] = true
+					enqueueJob(QueueItem{URL: abs, Depth: it.Depth + 1})
+				}
+				mu.Unlock()
+			}
 			for _, re := range regexes {
 				matches := re.FindAllStringSubmatch(body, -1)
 				for _, m := range matches {
 					candidate := ""
 					if len(m) >= 2 { candidate = m[1] } else if len(m) == 1 { candidate = m[0] }
-					abs := resolve(candidate, page)
-					if abs == "" { continue }
-					mu.Lock()
-					if !results[abs] && len(results) < maxPages {
-						results[abs] = true
-						pending.Add(1)
-						jobs <- item{u: abs, d: it.d + 1}
-					}
-					mu.Unlock()
+					enqueue(candidate)
 				}
 			}
+			// Meta-refresh and JS location-assignment redirects aren't real
+			// links, so the regular href/src patterns above miss them -
+			// SPAs and login flows often hop through these shims.
+			for _, t := range extractMetaRefreshTargets(body) { enqueue(t) }
+			for _, t := range extractJSRedirectTargets(body) { enqueue(t) }
 		}
 	}
 
@@ -149,11 +354,52 @@ func Crawl(startURL string, maxDepth int, maxPages int) ([]string, error) {
 		go worker()
 	}
 
-	pending.Add(1)
-	jobs <- item{u: startURL, d: 0}
+	// Resume from a prior checkpoint for this same start URL, if one exists,
+	// instead of re-seeding from scratch.
+	var resumed *Checkpoint
+	if opts.CheckpointPath != "" {
+		if cp, err := Resume(opts.CheckpointPath); err == nil && cp.StartURL == startURL {
+			resumed = cp
+		}
+	}
+
+	if resumed != nil {
+		mu.Lock()
+		for _, u := range resumed.Visited { visited[u] = true }
+		for _, u := range resumed.Results { results[u] = true }
+		mu.Unlock()
+		for _, it := range resumed.Queue {
+			enqueueJob(it)
+		}
+	} else {
+		if opts.SeedRobots || opts.SeedSitemap {
+			seeds, rules := seedFromRobotsAndSitemap(client, start, opts.SeedRobots, opts.SeedSitemap)
+			robots = rules
+			mu.Lock()
+			for _, s := range seeds {
+				if opts.SeedRobots && opts.Disallow == DisallowSkip && robots != nil {
+					if su, err := url.Parse(s); err == nil && su.Host == baseHost && !robots.allows(su.Path) {
+						continue
+					}
+				}
+				if !results[s] && len(results) < maxPages && canEnqueue(s) {
+					results[s] = true
+					enqueueJob(QueueItem{URL: s, Depth: 0})
+				}
+			}
+			mu.Unlock()
+		}
+
+		enqueueJob(QueueItem{URL: startURL, Depth: 0})
+	}
+
 	go func() { pending.Wait(); close(jobs) }()
 	wg.Wait()
 
+	if opts.CheckpointPath != "" {
+		_ = os.Remove(opts.CheckpointPath)
+	}
+
 	out := make([]string, 0, len(results))
 	for u := range results { out = append(out, u) }
 	return out, nil
@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// politeLimiter enforces a global RPS cap plus a per-host RPS cap, so a
+// crawl can be told to go easy on a target instead of hammering it with
+// however many workers happen to be configured.
+type politeLimiter struct {
+	global   *rate.Limiter
+	perHost  float64
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newPoliteLimiter(globalRPS, perHostRPS float64) *politeLimiter {
+	pl := &politeLimiter{perHost: perHostRPS, limiters: make(map[string]*rate.Limiter)}
+	if globalRPS > 0 {
+		pl.global = rate.NewLimiter(rate.Limit(globalRPS), burstFor(globalRPS))
+	}
+	return pl
+}
+
+func burstFor(rps float64) int {
+	b := int(rps)
+	if b < 1 {
+		b = 1
+	}
+	return b
+}
+
+// wait blocks until a request to host is allowed, honoring both the global
+// and per-host budgets. crawlDelay, when non-zero, overrides the per-host rate
+// with a robots.txt Crawl-delay (seconds) for that host instead.
+func (pl *politeLimiter) wait(host string, crawlDelay float64) {
+	if pl == nil {
+		return
+	}
+	if pl.global != nil {
+		pl.global.Wait(context.Background())
+	}
+	rps := pl.perHost
+	if crawlDelay > 0 {
+		rps = 1 / crawlDelay
+	}
+	if rps <= 0 {
+		return
+	}
+	pl.mu.Lock()
+	lim, ok := pl.limiters[host]
+	if !ok {
+		lim = rate.NewLimiter(rate.Limit(rps), burstFor(rps))
+		pl.limiters[host] = lim
+	}
+	pl.mu.Unlock()
+	lim.Wait(context.Background())
+}
@@ -0,0 +1,48 @@
+package crawler
+
+import (
+	"regexp"
+	"strings"
+)
+
+// metaRefreshRe pulls the content attribute out of <meta http-equiv="refresh">.
+var metaRefreshRe = regexp.MustCompile(`(?is)<meta[^>]+http-equiv\s*=\s*["']refresh["'][^>]*content\s*=\s*["']([^"']+)["']`)
+
+// jsLocationRe covers the common inline-script redirect shims:
+// location.href=, location.replace(, location.assign(, window.location=.
+var jsLocationRe = regexp.MustCompile(`(?i)(?:window\.)?location(?:\.href)?\s*(?:=|\.replace\(|\.assign\()\s*["']([^"']+)["']`)
+
+// extractMetaRefreshTargets returns the raw redirect targets named by any
+// <meta http-equiv="refresh" content="0;url=..."> tags in body.
+func extractMetaRefreshTargets(body string) []string {
+	var out []string
+	for _, m := range metaRefreshRe.FindAllStringSubmatch(body, -1) {
+		content := m[1]
+		parts := strings.SplitN(content, ";", 2)
+		target := ""
+		if len(parts) == 2 {
+			target = parts[1]
+		} else {
+			target = parts[0]
+		}
+		target = strings.TrimSpace(target)
+		if idx := strings.Index(strings.ToLower(target), "url="); idx != -1 {
+			target = target[idx+len("url="):]
+		}
+		target = strings.Trim(target, `"' `)
+		if target != "" {
+			out = append(out, target)
+		}
+	}
+	return out
+}
+
+// extractJSRedirectTargets returns the raw redirect targets named by inline
+// location.href=/.replace(/.assign( or window.location= assignments.
+func extractJSRedirectTargets(body string) []string {
+	var out []string
+	for _, m := range jsLocationRe.FindAllStringSubmatch(body, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// renderBudget caps how many browser tabs may render concurrently. Tabs are
+// far more expensive than plain HTTP workers, so this is tracked separately
+// from the regular crawl concurrency.
+type renderBudget struct {
+	sem chan struct{}
+}
+
+func newRenderBudget(n int) *renderBudget {
+	if n <= 0 {
+		n = 4
+	}
+	return &renderBudget{sem: make(chan struct{}, n)}
+}
+
+func (b *renderBudget) acquire() { b.sem <- struct{}{} }
+func (b *renderBudget) release() { <-b.sem }
+
+// browser wraps a single headless Chrome process (one exec allocator, one
+// browser-level chromedp context). Pages render in separate tabs created
+// from this shared context via chromedp.NewContext, rather than each
+// spawning their own Chrome process.
+type browser struct {
+	ctx    context.Context
+	cancel func()
+}
+
+// newBrowser launches one headless Chrome process and returns a context new
+// tabs can be created from with chromedp.NewContext(b.ctx).
+func newBrowser(ctx context.Context) *browser {
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, chromedp.DefaultExecAllocatorOptions[:]...)
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	return &browser{
+		ctx: browserCtx,
+		cancel: func() {
+			cancelBrowser()
+			cancelAlloc()
+		},
+	}
+}
+
+func (b *browser) Close() { b.cancel() }
+
+// renderPage drives a headless Chrome tab (a new tab in the shared browser)
+// to load pageURL, waits a fixed one second after navigation, and returns the
+// final rendered HTML plus every request URL observed via the CDP Network
+// domain (covers XHR/fetch calls that never show up in the static HTML at
+// all). It does not track in-flight requests, so requests issued after that
+// second are not captured.
+func renderPage(b *browser, pageURL string, timeout time.Duration) (html string, requestURLs []string, err error) {
+	tabCtx, cancelTab := chromedp.NewContext(b.ctx)
+	defer cancelTab()
+	tabCtx, cancelTimeout := context.WithTimeout(tabCtx, timeout)
+	defer cancelTimeout()
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var urls []string
+
+	chromedp.ListenTarget(tabCtx, func(ev interface{}) {
+		if e, ok := ev.(*network.EventRequestWillBeSent); ok {
+			u := e.Request.URL
+			mu.Lock()
+			if !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+			mu.Unlock()
+		}
+	})
+
+	err = chromedp.Run(tabCtx,
+		network.Enable(),
+		chromedp.Navigate(pageURL),
+		chromedp.Sleep(1*time.Second), // crude network-idle wait; good enough for most SPAs
+		chromedp.OuterHTML("html", &html, chromedp.ByQuery),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	mu.Lock()
+	requestURLs = append([]string(nil), urls...)
+	mu.Unlock()
+	return html, requestURLs, nil
+}
+
+// browserAvailable probes the shared browser's own context to see whether a
+// usable Chrome/Chromium binary actually launched, so CrawlRendered can fall
+// back to the plain regex crawler instead of hanging or failing mid-run.
+func browserAvailable(ctx context.Context) bool {
+	probeCtx, cancelTimeout := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelTimeout()
+	return chromedp.Run(probeCtx, chromedp.Navigate("about:blank")) == nil
+}
+
+// CrawlRendered behaves like Crawl but additionally renders each discovered
+// page in headless Chrome, extracting links from the rendered DOM and from
+// network requests the page issued (XHR/fetch), which the regex-only
+// extraction in Crawl can't see for SPA routes. If no browser is available it
+// falls straight back to Crawl.
+func CrawlRendered(startURL string, maxDepth int, maxPages int, opts Options) ([]string, error) {
+	start, err := url.Parse(startURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start url: %w", err)
+	}
+	baseHost := start.Host
+
+	b := newBrowser(context.Background())
+	defer b.Close()
+	if !browserAvailable(b.ctx) {
+		return Crawl(startURL, maxDepth, maxPages, opts)
+	}
+
+	regexResults, err := Crawl(startURL, maxDepth, maxPages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rendered-DOM links go through the same scope/robots policy the regex
+	// crawl applies via resolve(), so -render-js can't silently cover more
+	// ground than a plain crawl of the same site would.
+	var robots *robotsRules
+	if opts.SeedRobots {
+		robotsClient := &http.Client{Timeout: 10 * time.Second}
+		if r, err := fetchRobots(robotsClient, start); err == nil {
+			robots = r
+		}
+	}
+
+	budget := newRenderBudget(opts.RenderConcurrency)
+	extra := make(map[string]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, pageURL := range regexResults {
+		if strings.HasPrefix(pageURL, "javascript:") {
+			continue
+		}
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			budget.acquire()
+			defer budget.release()
+			html, reqURLs, err := renderPage(b, u, 20*time.Second)
+			if err != nil {
+				return
+			}
+			page, perr := url.Parse(u)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, link := range extractLinksFromHTML(html, u, baseHost, opts.Scope, robots, opts.Disallow, opts.KeepJSLinks) {
+				extra[link] = true
+			}
+			if perr == nil {
+				for _, link := range reqURLs {
+					if full := resolveLink(link, page, baseHost, opts.Scope, robots, opts.Disallow, opts.KeepJSLinks); full != "" {
+						extra[full] = true
+					}
+				}
+			}
+		}(pageURL)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, len(regexResults))
+	out := append([]string(nil), regexResults...)
+	for _, u := range regexResults {
+		seen[u] = true
+	}
+	for u := range extra {
+		if !seen[u] && len(out) < maxPages {
+			seen[u] = true
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,220 @@
+package crawler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DisallowPolicy controls what the crawler does with paths robots.txt marks Disallow.
+type DisallowPolicy int
+
+const (
+	// DisallowSkip never seeds or enqueues a disallowed path (default, zero value).
+	DisallowSkip DisallowPolicy = iota
+	// DisallowEnqueue seeds disallowed paths anyway, for coverage-oriented crawls.
+	DisallowEnqueue
+)
+
+// robotsRules holds the parsed "*" user-agent group of a robots.txt file.
+type robotsRules struct {
+	disallow   []string
+	allow      []string
+	sitemaps   []string
+	crawlDelay float64 // seconds; 0 means none was specified
+}
+
+// allows reports whether p is permitted under the parsed Allow/Disallow rules,
+// using the longest-match-wins convention most crawlers follow.
+func (r *robotsRules) allows(p string) bool {
+	bestAllow, bestDisallow := -1, -1
+	for _, rule := range r.allow {
+		if rule != "" && strings.HasPrefix(p, rule) && len(rule) > bestAllow {
+			bestAllow = len(rule)
+		}
+	}
+	for _, rule := range r.disallow {
+		if rule != "" && strings.HasPrefix(p, rule) && len(rule) > bestDisallow {
+			bestDisallow = len(rule)
+		}
+	}
+	if bestDisallow == -1 {
+		return true
+	}
+	return bestAllow >= bestDisallow
+}
+
+// disallowed returns the raw Disallow prefixes, used to synthesize seed URLs.
+func (r *robotsRules) disallowed() []string {
+	out := make([]string, 0, len(r.disallow))
+	for _, d := range r.disallow {
+		if d != "" {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// parseRobots parses a robots.txt body, collecting the "*" (and un-grouped)
+// user-agent rules plus any Sitemap: directives, which apply regardless of group.
+func parseRobots(body []byte) *robotsRules {
+	rules := &robotsRules{}
+	inWildcardGroup := true // rules before the first User-agent line apply to everyone
+	s := bufio.NewScanner(strings.NewReader(string(body)))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		val := strings.TrimSpace(parts[1])
+		switch key {
+		case "user-agent":
+			inWildcardGroup = val == "*"
+		case "disallow":
+			if inWildcardGroup {
+				rules.disallow = append(rules.disallow, val)
+			}
+		case "allow":
+			if inWildcardGroup {
+				rules.allow = append(rules.allow, val)
+			}
+		case "sitemap":
+			if val != "" {
+				rules.sitemaps = append(rules.sitemaps, val)
+			}
+		case "crawl-delay":
+			if inWildcardGroup {
+				if d, err := strconv.ParseFloat(val, 64); err == nil {
+					rules.crawlDelay = d
+				}
+			}
+		}
+	}
+	return rules
+}
+
+// fetchRobots fetches and parses /robots.txt relative to startURL's host.
+func fetchRobots(client *http.Client, start *url.URL) (*robotsRules, error) {
+	robotsURL := &url.URL{Scheme: start.Scheme, Host: start.Host, Path: "/robots.txt"}
+	resp, err := client.Get(robotsURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	return parseRobots(body), nil
+}
+
+// sitemapXML mirrors the subset of the sitemap/sitemapindex schemas we care about.
+type sitemapXML struct {
+	XMLName xml.Name `xml:"sitemapindex"`
+	Sitemap []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+type urlsetXML struct {
+	XMLName xml.Name `xml:"urlset"`
+	URL     []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// fetchSitemapURLs fetches sitemapURL (transparently decompressing .xml.gz) and
+// returns the page URLs it contains, recursing into nested sitemap indexes.
+func fetchSitemapURLs(client *http.Client, sitemapURL string, depth int) []string {
+	if depth > 5 {
+		return nil
+	}
+	resp, err := client.Get(sitemapURL)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var reader io.Reader = resp.Body
+	if strings.HasSuffix(strings.ToLower(sitemapURL), ".gz") || strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, 16*1024*1024))
+	if err != nil {
+		return nil
+	}
+
+	var index sitemapXML
+	if xml.Unmarshal(body, &index) == nil && len(index.Sitemap) > 0 {
+		var out []string
+		for _, sm := range index.Sitemap {
+			if sm.Loc == "" {
+				continue
+			}
+			out = append(out, fetchSitemapURLs(client, sm.Loc, depth+1)...)
+		}
+		return out
+	}
+
+	var set urlsetXML
+	if xml.Unmarshal(body, &set) == nil && len(set.URL) > 0 {
+		out := make([]string, 0, len(set.URL))
+		for _, u := range set.URL {
+			if u.Loc != "" {
+				out = append(out, u.Loc)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// seedFromRobotsAndSitemap fetches /robots.txt and any sitemaps it (or the
+// conventional /sitemap.xml location) references, returning seed URLs plus
+// the parsed rules so the caller can apply the disallow policy.
+func seedFromRobotsAndSitemap(client *http.Client, start *url.URL, seedRobots, seedSitemap bool) ([]string, *robotsRules) {
+	var seeds []string
+	rules := &robotsRules{}
+	if seedRobots {
+		if r, err := fetchRobots(client, start); err == nil {
+			rules = r
+		}
+	}
+	if seedSitemap {
+		sitemaps := append([]string{}, rules.sitemaps...)
+		if len(sitemaps) == 0 {
+			sitemaps = []string{(&url.URL{Scheme: start.Scheme, Host: start.Host, Path: "/sitemap.xml"}).String()}
+		}
+		for _, sm := range sitemaps {
+			seeds = append(seeds, fetchSitemapURLs(client, sm, 0)...)
+		}
+	}
+	if seedRobots {
+		for _, d := range rules.disallowed() {
+			seeds = append(seeds, (&url.URL{Scheme: start.Scheme, Host: start.Host, Path: d}).String())
+		}
+	}
+	return seeds, rules
+}
@@ -0,0 +1,99 @@
+package crawler
+
+import "testing"
+
+func TestRobotsAllowsLongestMatchWins(t *testing.T) {
+	rules := parseRobots([]byte(`
+User-agent: *
+Disallow: /admin
+Allow: /admin/public
+`))
+
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{"/admin", false},
+		{"/admin/secret", false},
+		{"/admin/public", true},
+		{"/admin/public/page", true},
+		{"/other", true},
+	}
+	for _, c := range cases {
+		if got := rules.allows(c.path); got != c.want {
+			t.Errorf("allows(%q) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestRobotsAllowsWithNoRulesAllowsEverything(t *testing.T) {
+	rules := parseRobots([]byte(""))
+	if !rules.allows("/anything") {
+		t.Fatalf("an empty ruleset should allow every path")
+	}
+}
+
+func TestRobotsOnlyWildcardGroupApplies(t *testing.T) {
+	rules := parseRobots([]byte(`
+User-agent: Googlebot
+Disallow: /only-google
+
+User-agent: *
+Disallow: /everyone
+`))
+
+	if rules.allows("/everyone") {
+		t.Fatalf("expected /everyone to be disallowed for the wildcard group")
+	}
+	if !rules.allows("/only-google") {
+		t.Fatalf("rules scoped to a non-wildcard user-agent must not apply to the wildcard group")
+	}
+}
+
+func TestRobotsUngroupedRulesBeforeFirstUserAgentApplyToWildcard(t *testing.T) {
+	rules := parseRobots([]byte(`
+Disallow: /legacy
+User-agent: *
+Disallow: /modern
+`))
+
+	if rules.allows("/legacy") {
+		t.Fatalf("rules before the first User-agent line should apply to the wildcard group")
+	}
+	if rules.allows("/modern") {
+		t.Fatalf("expected /modern to be disallowed")
+	}
+}
+
+func TestRobotsCrawlDelayAndSitemapParsed(t *testing.T) {
+	rules := parseRobots([]byte(`
+User-agent: *
+Crawl-delay: 2.5
+Sitemap: https://example.com/sitemap.xml
+Disallow: /private
+`))
+
+	if rules.crawlDelay != 2.5 {
+		t.Errorf("crawlDelay = %v, want 2.5", rules.crawlDelay)
+	}
+	if len(rules.sitemaps) != 1 || rules.sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("sitemaps = %v, want one entry", rules.sitemaps)
+	}
+	if rules.allows("/private") {
+		t.Fatalf("expected /private to be disallowed")
+	}
+}
+
+func TestRobotsDisallowedReturnsNonEmptyPrefixesOnly(t *testing.T) {
+	rules := parseRobots([]byte(`
+User-agent: *
+Disallow:
+Disallow: /a
+Disallow: /b
+`))
+
+	got := rules.disallowed()
+	if len(got) != 2 || got[0] != "/a" || got[1] != "/b" {
+		t.Errorf("disallowed() = %v, want [/a /b]", got)
+	}
+}
@@ -0,0 +1,91 @@
+package crawler
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Scope controls which discovered URLs the crawler is allowed to follow. The
+// zero value reproduces the original behavior: only the exact start host.
+type Scope struct {
+	// IncludeSubdomains also matches *.baseHost, not just baseHost itself.
+	IncludeSubdomains bool
+	// AllowHosts adds these hosts (exact or glob, e.g. "*.example.com") to
+	// the hosts in scope, alongside baseHost/IncludeSubdomains.
+	AllowHosts []string
+	// DenyHosts excludes these hosts (exact or glob) even if otherwise in scope.
+	DenyHosts []string
+	// IncludeRegex, if non-empty, requires the full URL to match at least one.
+	IncludeRegex []*regexp.Regexp
+	// ExcludeRegex drops any URL matching any of these, regardless of IncludeRegex.
+	ExcludeRegex []*regexp.Regexp
+	// MaxPagesPerHost caps how many pages are crawled per host, keyed by
+	// host, so one large in-scope subdomain can't consume the whole
+	// maxPages budget. A host with no entry, or a zero entry, falls back
+	// to the crawl-wide maxPages.
+	MaxPagesPerHost map[string]int
+}
+
+// hostInScope reports whether host is allowed by the subdomain/allow/deny rules.
+func (s Scope) hostInScope(host, baseHost string) bool {
+	if matchesHost(host, baseHost) {
+		return !s.hostDenied(host)
+	}
+	if s.IncludeSubdomains && strings.HasSuffix(host, "."+baseHost) {
+		return !s.hostDenied(host)
+	}
+	for _, allow := range s.AllowHosts {
+		if matchesHost(host, allow) {
+			return !s.hostDenied(host)
+		}
+	}
+	return false
+}
+
+func (s Scope) hostDenied(host string) bool {
+	for _, deny := range s.DenyHosts {
+		if matchesHost(host, deny) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHost compares host against pattern, supporting a leading "*." glob.
+func matchesHost(host, pattern string) bool {
+	if ok, err := path.Match(pattern, host); err == nil && ok {
+		return true
+	}
+	return host == pattern
+}
+
+// allowsURL applies the include/exclude regex policy to a full URL string.
+func (s Scope) allowsURL(fullURL string) bool {
+	for _, re := range s.ExcludeRegex {
+		if re.MatchString(fullURL) {
+			return false
+		}
+	}
+	if len(s.IncludeRegex) == 0 {
+		return true
+	}
+	for _, re := range s.IncludeRegex {
+		if re.MatchString(fullURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// pageBudget returns the max pages allowed for host, falling back to the
+// crawl-wide maxPages when no per-host cap was configured.
+func (s Scope) pageBudget(host string, maxPages int) int {
+	if s.MaxPagesPerHost == nil {
+		return maxPages
+	}
+	if n, ok := s.MaxPagesPerHost[host]; ok && n > 0 {
+		return n
+	}
+	return maxPages
+}
@@ -0,0 +1,168 @@
+package fingerprint
+
+import (
+	"strconv"
+	"strings"
+)
+
+// dslContext is the set of variables/functions a DSL expression can see.
+type dslContext struct {
+	body       string
+	statusCode int
+	header     string
+	title      string
+	cookie     string
+}
+
+// evalDSL evaluates a small expression subset good enough for the rule
+// signatures this package targets: contains(<field>, "literal"),
+// status_code <op> N, and len(<field>) <op> N, combined with && and ||.
+// It is not a general-purpose expression language by design — just enough
+// DSL to express common fingerprinting conditions without a dependency.
+func evalDSL(expr string, ctx dslContext) bool {
+	expr = strings.TrimSpace(expr)
+	if or := splitTopLevel(expr, "||"); len(or) > 1 {
+		for _, part := range or {
+			if evalDSL(part, ctx) {
+				return true
+			}
+		}
+		return false
+	}
+	if and := splitTopLevel(expr, "&&"); len(and) > 1 {
+		for _, part := range and {
+			if !evalDSL(part, ctx) {
+				return false
+			}
+		}
+		return true
+	}
+	return evalAtom(strings.TrimSpace(trimParens(expr)), ctx)
+}
+
+// splitTopLevel splits expr on sep, ignoring occurrences inside quotes or parens.
+func splitTopLevel(expr, sep string) []string {
+	var parts []string
+	depth := 0
+	inStr := false
+	last := 0
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '"', '\'':
+			inStr = !inStr
+		case '(':
+			if !inStr { depth++ }
+		case ')':
+			if !inStr { depth-- }
+		}
+		if !inStr && depth == 0 && i+len(sep) <= len(expr) && expr[i:i+len(sep)] == sep {
+			parts = append(parts, expr[last:i])
+			i += len(sep) - 1
+			last = i + 1
+		}
+	}
+	parts = append(parts, expr[last:])
+	return parts
+}
+
+func trimParens(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		return expr[1 : len(expr)-1]
+	}
+	return expr
+}
+
+func evalAtom(expr string, ctx dslContext) bool {
+	switch {
+	case strings.HasPrefix(expr, "contains("):
+		args := splitArgs(strings.TrimSuffix(strings.TrimPrefix(expr, "contains("), ")"))
+		if len(args) != 2 {
+			return false
+		}
+		field := resolveField(strings.TrimSpace(args[0]), ctx)
+		needle := unquote(strings.TrimSpace(args[1]))
+		return strings.Contains(field, needle)
+	case strings.HasPrefix(expr, "len("):
+		return evalComparison(expr, ctx)
+	case strings.HasPrefix(expr, "status_code"):
+		return evalComparison(expr, ctx)
+	default:
+		return false
+	}
+}
+
+func splitArgs(s string) []string {
+	parts := splitTopLevel(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func resolveField(name string, ctx dslContext) string {
+	switch name {
+	case "body":
+		return ctx.body
+	case "header":
+		return ctx.header
+	case "title":
+		return ctx.title
+	case "cookie":
+		return ctx.cookie
+	default:
+		return ""
+	}
+}
+
+// evalComparison handles "<lhs> <op> <rhs>" where lhs is status_code or
+// len(field) and op is one of == != > >= < <=.
+func evalComparison(expr string, ctx dslContext) bool {
+	for _, op := range []string{"==", "!=", ">=", "<=", ">", "<"} {
+		idx := strings.Index(expr, op)
+		if idx == -1 {
+			continue
+		}
+		lhs := strings.TrimSpace(expr[:idx])
+		rhs := strings.TrimSpace(expr[idx+len(op):])
+		lv := evalNumericOperand(lhs, ctx)
+		rv, err := strconv.Atoi(rhs)
+		if err != nil {
+			return false
+		}
+		switch op {
+		case "==":
+			return lv == rv
+		case "!=":
+			return lv != rv
+		case ">=":
+			return lv >= rv
+		case "<=":
+			return lv <= rv
+		case ">":
+			return lv > rv
+		case "<":
+			return lv < rv
+		}
+	}
+	return false
+}
+
+func evalNumericOperand(expr string, ctx dslContext) int {
+	if expr == "status_code" {
+		return ctx.statusCode
+	}
+	if strings.HasPrefix(expr, "len(") && strings.HasSuffix(expr, ")") {
+		field := resolveField(strings.TrimSpace(expr[4:len(expr)-1]), ctx)
+		return len(field)
+	}
+	n, _ := strconv.Atoi(expr)
+	return n
+}
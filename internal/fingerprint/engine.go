@@ -0,0 +1,190 @@
+package fingerprint
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Result is one matched rule against a single response, along with whatever
+// it captured (e.g. a version string pulled out by a named regex group).
+type Result struct {
+	RuleName string
+	Captures map[string]string
+}
+
+// Engine holds a compiled ruleset ready to Match against responses.
+type Engine struct {
+	rules []*Rule
+}
+
+// Load reads a YAML ruleset (a list of Rule documents) from path and compiles
+// every regex matcher up front.
+func Load(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*Rule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r.Condition == "" {
+			r.Condition = ConditionAnd
+		}
+		if err := r.compile(); err != nil {
+			return nil, err
+		}
+	}
+	return &Engine{rules: rules}, nil
+}
+
+var titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+func extractTitle(body []byte) string {
+	m := titleRe.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(string(m[1]))
+}
+
+func headerBlob(h http.Header) string {
+	var b strings.Builder
+	for k, vs := range h {
+		for _, v := range vs {
+			b.WriteString(k)
+			b.WriteString(": ")
+			b.WriteString(v)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func cookieBlob(h http.Header) string {
+	var b strings.Builder
+	for _, v := range h.Values("Set-Cookie") {
+		b.WriteString(v)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func partValue(part Part, body, header, title, cookie string) string {
+	switch part {
+	case PartHeader:
+		return header
+	case PartTitle:
+		return title
+	case PartCookie:
+		return cookie
+	default:
+		return body
+	}
+}
+
+// matchMatcher evaluates one Matcher against a response, returning whether
+// it matched and, if Name is set, the value it captured.
+func matchMatcher(m *Matcher, resp *http.Response, ctx dslContext, body, header, title, cookie string) (bool, string) {
+	switch m.Type {
+	case MatcherStatus:
+		for _, s := range m.Status {
+			if s == resp.StatusCode {
+				return true, ""
+			}
+		}
+		return false, ""
+	case MatcherWord:
+		target := partValue(m.Part, body, header, title, cookie)
+		for _, w := range m.Words {
+			if strings.Contains(target, w) {
+				return true, w
+			}
+		}
+		return false, ""
+	case MatcherRegex:
+		target := partValue(m.Part, body, header, title, cookie)
+		for _, re := range m.compiled {
+			if loc := re.FindStringSubmatch(target); loc != nil {
+				group := m.Group
+				if group < 0 || group >= len(loc) {
+					group = 0
+				}
+				return true, loc[group]
+			}
+		}
+		return false, ""
+	case MatcherDSL:
+		for _, expr := range m.DSL {
+			if evalDSL(expr, ctx) {
+				return true, ""
+			}
+		}
+		return false, ""
+	default:
+		return false, ""
+	}
+}
+
+func requireSatisfied(r *Rule, matched map[string]map[string]string) bool {
+	for _, dep := range r.Require {
+		if _, ok := matched[dep]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func evalRule(r *Rule, resp *http.Response, body []byte, header, title, cookie string) (bool, map[string]string) {
+	ctx := dslContext{body: string(body), statusCode: resp.StatusCode, header: header, title: title, cookie: cookie}
+	caps := map[string]string{}
+	matchedAny := false
+	for i := range r.Matchers {
+		ok, val := matchMatcher(&r.Matchers[i], resp, ctx, string(body), header, title, cookie)
+		if ok {
+			matchedAny = true
+			if r.Matchers[i].Name != "" && val != "" {
+				caps[r.Matchers[i].Name] = val
+			}
+			if r.Condition == ConditionOr {
+				return true, caps
+			}
+		} else if r.Condition == ConditionAnd {
+			return false, nil
+		}
+	}
+	if r.Condition == ConditionOr {
+		return matchedAny, caps
+	}
+	return true, caps
+}
+
+// Match runs every compiled rule against resp/body, in ruleset order,
+// skipping a rule as soon as one of its Require dependencies hasn't matched
+// yet for this response. That ordering matters: write prerequisite rules
+// before the rules that require them.
+func (e *Engine) Match(resp *http.Response, body []byte) []Result {
+	header := headerBlob(resp.Header)
+	title := extractTitle(body)
+	cookie := cookieBlob(resp.Header)
+
+	matched := make(map[string]map[string]string)
+	var results []Result
+	for _, r := range e.rules {
+		if !requireSatisfied(r, matched) {
+			continue
+		}
+		ok, caps := evalRule(r, resp, body, header, title, cookie)
+		if !ok {
+			continue
+		}
+		matched[r.Name] = caps
+		results = append(results, Result{RuleName: r.Name, Captures: caps})
+	}
+	return results
+}
@@ -0,0 +1,123 @@
+package fingerprint
+
+import (
+	"net/http"
+	"testing"
+)
+
+func newResp(status int) *http.Response {
+	return &http.Response{StatusCode: status, Header: http.Header{}}
+}
+
+func TestMatchSkipsRuleWhenRequireNotSatisfied(t *testing.T) {
+	e := &Engine{rules: []*Rule{
+		{
+			Name:      "nginx",
+			Condition: ConditionAnd,
+			Matchers:  []Matcher{{Type: MatcherWord, Part: PartHeader, Words: []string{"nope-not-present"}}},
+		},
+		{
+			Name:      "nginx-version",
+			Require:   []string{"nginx"},
+			Condition: ConditionAnd,
+			Matchers:  []Matcher{{Type: MatcherStatus, Status: []int{200}}},
+		},
+	}}
+
+	results := e.Match(newResp(200), []byte("hello"))
+	for _, r := range results {
+		if r.RuleName == "nginx-version" {
+			t.Fatalf("nginx-version matched even though its prerequisite rule %q never matched", "nginx")
+		}
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected no rules to match, got %+v", results)
+	}
+}
+
+func TestMatchRunsRuleOnceRequireIsSatisfied(t *testing.T) {
+	e := &Engine{rules: []*Rule{
+		{
+			Name:      "nginx",
+			Condition: ConditionAnd,
+			Matchers:  []Matcher{{Type: MatcherStatus, Status: []int{200}}},
+		},
+		{
+			Name:      "nginx-version",
+			Require:   []string{"nginx"},
+			Condition: ConditionAnd,
+			Matchers:  []Matcher{{Type: MatcherStatus, Status: []int{200}}},
+		},
+	}}
+
+	results := e.Match(newResp(200), []byte("hello"))
+	names := make(map[string]bool, len(results))
+	for _, r := range results {
+		names[r.RuleName] = true
+	}
+	if !names["nginx"] || !names["nginx-version"] {
+		t.Fatalf("expected both nginx and nginx-version to match once the prerequisite is satisfied, got %+v", results)
+	}
+}
+
+func TestMatchHonorsRuleOrderForRequire(t *testing.T) {
+	// A rule can only require a rule that appears earlier in the ruleset;
+	// Match evaluates rules in order and skips any whose Require hasn't
+	// been satisfied yet, so listing the dependent rule first means it
+	// never matches even when its own condition would otherwise fire.
+	e := &Engine{rules: []*Rule{
+		{
+			Name:      "dependent",
+			Require:   []string{"base"},
+			Condition: ConditionAnd,
+			Matchers:  []Matcher{{Type: MatcherStatus, Status: []int{200}}},
+		},
+		{
+			Name:      "base",
+			Condition: ConditionAnd,
+			Matchers:  []Matcher{{Type: MatcherStatus, Status: []int{200}}},
+		},
+	}}
+
+	results := e.Match(newResp(200), []byte("hello"))
+	for _, r := range results {
+		if r.RuleName == "dependent" {
+			t.Fatalf("dependent rule matched despite being listed before its prerequisite")
+		}
+	}
+}
+
+func TestMatchWordMatcherOnCookie(t *testing.T) {
+	resp := newResp(200)
+	resp.Header.Set("Set-Cookie", "sessionid=abc; Path=/; laravel_session=xyz")
+	e := &Engine{rules: []*Rule{
+		{
+			Name:      "laravel",
+			Condition: ConditionAnd,
+			Matchers:  []Matcher{{Type: MatcherWord, Part: PartCookie, Words: []string{"laravel_session"}}},
+		},
+	}}
+
+	results := e.Match(resp, []byte("body"))
+	if len(results) != 1 || results[0].RuleName != "laravel" {
+		t.Fatalf("expected laravel rule to match on the Set-Cookie header, got %+v", results)
+	}
+}
+
+func TestMatchConditionOrNeedsOnlyOneMatcher(t *testing.T) {
+	e := &Engine{rules: []*Rule{
+		{
+			Name:      "either",
+			Condition: ConditionOr,
+			Matchers: []Matcher{
+				{Type: MatcherStatus, Status: []int{404}},
+				{Type: MatcherStatus, Status: []int{200}},
+			},
+		},
+	}}
+
+	results := e.Match(newResp(200), []byte("body"))
+	if len(results) != 1 {
+		t.Fatalf("expected the or-rule to match via its second matcher, got %+v", results)
+	}
+}
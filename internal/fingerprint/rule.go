@@ -0,0 +1,87 @@
+package fingerprint
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Part identifies which piece of an HTTP exchange a matcher inspects.
+type Part string
+
+const (
+	PartBody   Part = "body"
+	PartHeader Part = "header"
+	PartTitle  Part = "title"
+	PartCookie Part = "cookie"
+)
+
+// MatcherType selects how a Matcher's patterns are evaluated.
+type MatcherType string
+
+const (
+	MatcherWord   MatcherType = "word"
+	MatcherRegex  MatcherType = "regex"
+	MatcherDSL    MatcherType = "dsl"
+	MatcherStatus MatcherType = "status"
+)
+
+// Matcher is a single condition within a Rule. Exactly one of Words, Regex,
+// DSL, or Status is populated, depending on Type.
+type Matcher struct {
+	Type MatcherType `yaml:"type"`
+	Part Part        `yaml:"part"`
+
+	Words []string `yaml:"words"`
+	Regex []string `yaml:"regex"`
+	DSL   []string `yaml:"dsl"`
+	Status []int   `yaml:"status"`
+
+	// Name, when set, captures the matched text (e.g. a version string)
+	// under this key in the per-rule capture map.
+	Name string `yaml:"name"`
+	// Group selects which regex submatch group to capture (0 = whole match).
+	Group int `yaml:"group"`
+
+	compiled []*regexp.Regexp
+}
+
+// Condition is how a Rule's Matchers combine: "and" requires all of them to
+// match, "or" requires at least one.
+type Condition string
+
+const (
+	ConditionAnd Condition = "and"
+	ConditionOr  Condition = "or"
+)
+
+// Rule describes one tech-stack signature: the matchers that must fire and,
+// optionally, other rules that must already have matched first.
+type Rule struct {
+	Name string `yaml:"name"`
+	// Require names other rules that must already be present in a URL's
+	// result map before this rule is even evaluated. This lets a large
+	// ruleset skip whole branches instead of running every matcher against
+	// every response.
+	Require   []string  `yaml:"require"`
+	Condition Condition `yaml:"matchers-condition"`
+	Matchers  []Matcher `yaml:"matchers"`
+}
+
+// compile compiles every regex matcher's patterns once, at load time.
+func (r *Rule) compile() error {
+	for i := range r.Matchers {
+		m := &r.Matchers[i]
+		if m.Type != MatcherRegex {
+			continue
+		}
+		m.compiled = make([]*regexp.Regexp, 0, len(m.Regex))
+		for _, p := range m.Regex {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("rule %q: bad regex %q: %w", r.Name, p, err)
+			}
+			m.compiled = append(m.compiled, re)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Package logx gives PathSeeker a single place to emit structured,
+// line-delimited JSON log events, so -debug output and the -events stream
+// share one format instead of debug messages being free-form text while
+// events are JSON.
+package logx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event is one structured log line. Debug messages use Type "debug" and
+// set Message; scan lifecycle events (request, reflective_detected,
+// recursion_skipped, queue_full, scan_complete) set Type to their name and
+// carry their details in Data.
+type Event struct {
+	Time    string                 `json:"time"`
+	Type    string                 `json:"type"`
+	Message string                 `json:"message,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Logger writes Events to w as one JSON object per line. Safe for
+// concurrent use.
+type Logger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// New wraps w as a structured event logger.
+func New(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+func (l *Logger) write(evt Event) {
+	evt.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintln(l.w, string(data))
+}
+
+// Debugf logs a free-form debug message as a structured "debug" event.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.write(Event{Type: "debug", Message: fmt.Sprintf(format, args...)})
+}
+
+// Event logs a named lifecycle event with structured data.
+func (l *Logger) Event(eventType string, data map[string]interface{}) {
+	l.write(Event{Type: eventType, Data: data})
+}
@@ -0,0 +1,379 @@
+// Package store provides BoltDB-backed, crash-resumable state for
+// PathSeeker's recursive fuzzing loop: a persisted copy of the task queue,
+// the seen set, and the shortest-URL-per-content map. Workers still take
+// tasks from main's in-memory channel; the persisted queue is read back
+// with Dequeue only when a scan resumes.
+package store
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketQueue    = []byte("queue")
+	bucketSeen     = []byte("seen")
+	bucketHashBest = []byte("hashbest")
+	bucketMeta     = []byte("meta")
+)
+
+// Task mirrors main's reqTask, persisted as the queue bucket's value.
+// InFlight marks a task a worker has pulled but not yet completed; a crash
+// leaves it true until RequeueInFlight resets it at the next startup.
+type Task struct {
+	Base       string `json:"base"`
+	Prefix     string `json:"prefix"`
+	Word       string `json:"word"`
+	Depth      int    `json:"depth"`
+	WithSlash  bool   `json:"with_slash"`
+	ErrorCount int    `json:"error_count"`
+	InFlight   bool   `json:"in_flight"`
+}
+
+// Store wraps a BoltDB handle holding the queue/seen/hashbest/meta buckets.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open creates (or reopens) the state file at path, ensuring all four
+// buckets exist.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketQueue, bucketSeen, bucketHashBest, bucketMeta} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying BoltDB file.
+func (s *Store) Close() error { return s.db.Close() }
+
+func seqKey(seq uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, seq)
+	return b
+}
+
+// EnqueueOne adds a single task to the pending queue under a fresh
+// monotonic sequence key and returns that sequence, used later to Complete it.
+func (s *Store) EnqueueOne(t Task) (uint64, error) {
+	var seq uint64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketQueue)
+		var err error
+		seq, err = b.NextSequence()
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		return b.Put(seqKey(seq), data)
+	})
+	return seq, err
+}
+
+// RecordHitAndEnqueue marks parentURL as seen (with its status) and enqueues
+// its recursion children in a single transaction, so a crash can't leave the
+// parent's hit recorded without its children queued (or vice versa).
+func (s *Store) RecordHitAndEnqueue(parentURL string, parentStatus int, children []Task) ([]uint64, error) {
+	seqs := make([]uint64, len(children))
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		seenB := tx.Bucket(bucketSeen)
+		if err := seenB.Put(urlKey(parentURL), statusBytes(parentStatus)); err != nil {
+			return err
+		}
+		qb := tx.Bucket(bucketQueue)
+		for i, t := range children {
+			seq, err := qb.NextSequence()
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(t)
+			if err != nil {
+				return err
+			}
+			if err := qb.Put(seqKey(seq), data); err != nil {
+				return err
+			}
+			seqs[i] = seq
+		}
+		return nil
+	})
+	return seqs, err
+}
+
+// Dequeue pulls the first task not already marked in-flight, flips its
+// in-flight flag, and returns it along with the sequence needed to Complete it.
+func (s *Store) Dequeue() (seq uint64, task Task, ok bool, err error) {
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketQueue)
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var t Task
+			if jsonErr := json.Unmarshal(v, &t); jsonErr != nil {
+				continue
+			}
+			if t.InFlight {
+				continue
+			}
+			t.InFlight = true
+			data, marshalErr := json.Marshal(t)
+			if marshalErr != nil {
+				return marshalErr
+			}
+			if putErr := b.Put(k, data); putErr != nil {
+				return putErr
+			}
+			seq = binary.BigEndian.Uint64(k)
+			task = t
+			ok = true
+			return nil
+		}
+		return nil
+	})
+	return seq, task, ok, err
+}
+
+// Complete removes a task from the queue once a worker finishes processing it.
+func (s *Store) Complete(seq uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketQueue).Delete(seqKey(seq))
+	})
+}
+
+// RequeueInFlight clears the in-flight flag on every queued task, so work
+// left mid-flight by a crash is picked up again. Call once at startup,
+// before any workers start pulling.
+func (s *Store) RequeueInFlight() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketQueue)
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var t Task
+			if err := json.Unmarshal(v, &t); err != nil {
+				continue
+			}
+			if !t.InFlight {
+				continue
+			}
+			t.InFlight = false
+			data, err := json.Marshal(t)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(k, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ClearPendingClaims removes every seen-bucket entry still holding the
+// claimed-but-pending placeholder (CheckAndMarkSeen claimed the URL but the
+// worker crashed before calling MarkSeen with a real status). Call once at
+// startup alongside RequeueInFlight, before any task is re-dequeued, so a
+// retried task's CheckAndMarkSeen isn't blocked by its own stale claim.
+func (s *Store) ClearPendingClaims() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSeen)
+		c := b.Cursor()
+		var stale [][]byte
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if statusValue(v) == claimedPlaceholderStatus {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// QueueLen returns how many tasks (in-flight or not) remain pending.
+func (s *Store) QueueLen() (int, error) {
+	n := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(bucketQueue).Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+func statusBytes(status int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(status))
+	return b
+}
+
+func statusValue(b []byte) int {
+	return int(int32(binary.BigEndian.Uint32(b)))
+}
+
+// Seen reports whether url has already been marked seen (regardless of the
+// status it was seen with). This is true for a claimed-but-not-yet-completed
+// entry too; use Completed to tell those apart.
+func (s *Store) Seen(url string) (bool, error) {
+	var seen bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		seen = tx.Bucket(bucketSeen).Get(urlKey(url)) != nil
+		return nil
+	})
+	return seen, err
+}
+
+// Completed reports whether url finished with a real HTTP status, as
+// opposed to merely being claimed via CheckAndMarkSeen. A claim whose
+// worker crashed before calling MarkSeen still holds the placeholder
+// status, so Completed reports false for it - unlike Seen, which would
+// wrongly call it done. Resume uses Completed, not Seen, to decide whether
+// a dequeued task's request still needs to fire.
+func (s *Store) Completed(url string) (bool, error) {
+	var completed bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketSeen).Get(urlKey(url))
+		if v == nil {
+			return nil
+		}
+		completed = statusValue(v) != claimedPlaceholderStatus
+		return nil
+	})
+	return completed, err
+}
+
+// CheckAndMarkSeen atomically checks whether url has already been claimed
+// and, if not, claims it with a placeholder status in the same Bolt
+// transaction - mirroring sync.Map.LoadOrStore's atomic check-and-set so two
+// workers racing for the same URL (a duplicate wordlist entry, or overlap
+// between a resumed queue and a fresh seed) can't both pass and both fire
+// the request. The caller should overwrite the placeholder with the real
+// status via MarkSeen once the request completes.
+func (s *Store) CheckAndMarkSeen(url string) (alreadyClaimed bool, err error) {
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSeen)
+		key := urlKey(url)
+		if b.Get(key) != nil {
+			alreadyClaimed = true
+			return nil
+		}
+		return b.Put(key, statusBytes(claimedPlaceholderStatus))
+	})
+	return alreadyClaimed, err
+}
+
+// claimedPlaceholderStatus marks a seen-bucket entry as claimed-but-pending,
+// before the real HTTP status is known. Not a valid HTTP status, so it can't
+// collide with a real MarkSeen value.
+const claimedPlaceholderStatus = -1
+
+// MarkSeen records url (keyed by its SHA1) as seen with the given status.
+func (s *Store) MarkSeen(url string, status int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSeen).Put(urlKey(url), statusBytes(status))
+	})
+}
+
+// urlKey hashes url with SHA1 so the seen bucket's keys stay a fixed size
+// regardless of how long the scanned URLs get.
+func urlKey(url string) []byte {
+	h := sha1.Sum([]byte(url))
+	return []byte(fmt.Sprintf("%x", h))
+}
+
+// HashBest returns the shortest known URL for a branch|contenthash key.
+func (s *Store) HashBest(key string) (string, bool, error) {
+	var val string
+	var ok bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketHashBest).Get([]byte(key))
+		if v != nil {
+			val = string(v)
+			ok = true
+		}
+		return nil
+	})
+	return val, ok, err
+}
+
+// SetHashBest records url as the shortest known URL for key.
+func (s *Store) SetHashBest(key, url string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketHashBest).Put([]byte(key), []byte(url))
+	})
+}
+
+// AllHashBest returns every branch|contenthash -> shortest-URL mapping
+// recorded so far, for final output once a scan finishes.
+func (s *Store) AllHashBest() (map[string]string, error) {
+	out := make(map[string]string)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketHashBest).ForEach(func(k, v []byte) error {
+			out[string(k)] = string(v)
+			return nil
+		})
+	})
+	return out, err
+}
+
+// SaveMeta persists a scan config/progress value (e.g. base URL, wordlist hash).
+func (s *Store) SaveMeta(key, value string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketMeta).Put([]byte(key), []byte(value))
+	})
+}
+
+// LoadMeta reads back a value saved with SaveMeta.
+func (s *Store) LoadMeta(key string) (string, bool, error) {
+	var val string
+	var ok bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketMeta).Get([]byte(key))
+		if v != nil {
+			val = string(v)
+			ok = true
+		}
+		return nil
+	})
+	return val, ok, err
+}
+
+// Matches reports whether this state file's persisted base URL and
+// wordlist hash match the current scan's, so Resume can tell a
+// continuation of the same scan from a stale/unrelated state file.
+func (s *Store) Matches(baseURL, wordlistSHA1 string) bool {
+	storedBase, ok := s.loadMetaOrEmpty("base_url")
+	if !ok || storedBase != baseURL {
+		return false
+	}
+	storedWL, ok := s.loadMetaOrEmpty("wordlist_sha1")
+	return ok && storedWL == wordlistSHA1
+}
+
+func (s *Store) loadMetaOrEmpty(key string) (string, bool) {
+	v, ok, err := s.LoadMeta(key)
+	if err != nil {
+		return "", false
+	}
+	return v, ok
+}
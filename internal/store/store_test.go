@@ -0,0 +1,109 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestCheckAndMarkSeenClaimsOnce(t *testing.T) {
+	s := openTestStore(t)
+
+	alreadyClaimed, err := s.CheckAndMarkSeen("http://example.com/a")
+	if err != nil {
+		t.Fatalf("CheckAndMarkSeen: %v", err)
+	}
+	if alreadyClaimed {
+		t.Fatalf("first claim of a URL must not report alreadyClaimed")
+	}
+
+	alreadyClaimed, err = s.CheckAndMarkSeen("http://example.com/a")
+	if err != nil {
+		t.Fatalf("CheckAndMarkSeen: %v", err)
+	}
+	if !alreadyClaimed {
+		t.Fatalf("second claim of the same URL must report alreadyClaimed, closing the duplicate-request race")
+	}
+}
+
+func TestCompletedFalseForClaimedButNotMarkedSeen(t *testing.T) {
+	s := openTestStore(t)
+
+	if _, err := s.CheckAndMarkSeen("http://example.com/in-flight"); err != nil {
+		t.Fatalf("CheckAndMarkSeen: %v", err)
+	}
+
+	// Simulate a crash between CheckAndMarkSeen and MarkSeen: the seen bucket
+	// holds only the claimed placeholder, never a real status.
+	completed, err := s.Completed("http://example.com/in-flight")
+	if err != nil {
+		t.Fatalf("Completed: %v", err)
+	}
+	if completed {
+		t.Fatalf("a claimed-but-never-completed URL must not report Completed=true, or resume would drop it")
+	}
+
+	seen, err := s.Seen("http://example.com/in-flight")
+	if err != nil {
+		t.Fatalf("Seen: %v", err)
+	}
+	if !seen {
+		t.Fatalf("Seen should still report true for a claimed entry")
+	}
+}
+
+func TestCompletedTrueAfterMarkSeen(t *testing.T) {
+	s := openTestStore(t)
+
+	if _, err := s.CheckAndMarkSeen("http://example.com/done"); err != nil {
+		t.Fatalf("CheckAndMarkSeen: %v", err)
+	}
+	if err := s.MarkSeen("http://example.com/done", 200); err != nil {
+		t.Fatalf("MarkSeen: %v", err)
+	}
+
+	completed, err := s.Completed("http://example.com/done")
+	if err != nil {
+		t.Fatalf("Completed: %v", err)
+	}
+	if !completed {
+		t.Fatalf("a URL marked seen with a real status must report Completed=true")
+	}
+}
+
+func TestClearPendingClaimsRemovesOnlyPlaceholders(t *testing.T) {
+	s := openTestStore(t)
+
+	if _, err := s.CheckAndMarkSeen("http://example.com/in-flight"); err != nil {
+		t.Fatalf("CheckAndMarkSeen: %v", err)
+	}
+	if _, err := s.CheckAndMarkSeen("http://example.com/finished"); err != nil {
+		t.Fatalf("CheckAndMarkSeen: %v", err)
+	}
+	if err := s.MarkSeen("http://example.com/finished", 200); err != nil {
+		t.Fatalf("MarkSeen: %v", err)
+	}
+
+	if err := s.ClearPendingClaims(); err != nil {
+		t.Fatalf("ClearPendingClaims: %v", err)
+	}
+
+	if seen, _ := s.Seen("http://example.com/in-flight"); seen {
+		t.Fatalf("the stale in-flight claim should have been cleared so the retried request isn't blocked")
+	}
+	if seen, _ := s.Seen("http://example.com/finished"); !seen {
+		t.Fatalf("a completed entry must survive ClearPendingClaims")
+	}
+	if completed, _ := s.Completed("http://example.com/finished"); !completed {
+		t.Fatalf("a completed entry must still report Completed=true after ClearPendingClaims")
+	}
+}
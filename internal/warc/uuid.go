@@ -0,0 +1,17 @@
+package warc
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// newUUID returns a random (v4) UUID string, used for WARC-Record-ID values.
+// A hand-rolled generator avoids pulling in a dependency for sixteen random
+// bytes formatted per RFC 4122.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
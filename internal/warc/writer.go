@@ -0,0 +1,163 @@
+package warc
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Writer streams request/response pairs to a gzipped ISO 28500 WARC file. It
+// is safe for concurrent use from multiple goroutines (a single mutex guards
+// the whole gzip stream, which is fine at fuzzing request rates).
+type Writer struct {
+	mu          sync.Mutex
+	path        string
+	maxSize     int64
+	rotation    int
+	f           *os.File
+	gz          *gzip.Writer
+	writtenSize int64
+}
+
+// NewWriter opens path for a fresh WARC stream and writes the leading
+// warcinfo record. maxSizeBytes, when positive, rotates to path+".N" once
+// the current file exceeds it.
+func NewWriter(path string, maxSizeBytes int64) (*Writer, error) {
+	w := &Writer{path: path, maxSize: maxSizeBytes}
+	if err := w.openFile(path); err != nil {
+		return nil, err
+	}
+	if err := w.writeWarcinfo(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) openFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w.f = f
+	w.gz = gzip.NewWriter(f)
+	w.writtenSize = 0
+	return nil
+}
+
+func (w *Writer) writeWarcinfo() error {
+	body := []byte("software: PathSeeker\r\nformat: WARC File Format 1.0\r\n")
+	return w.writeRecord("warcinfo", map[string]string{
+		"Content-Type": "application/warc-fields",
+	}, body)
+}
+
+// writeRecord writes one WARC record with a freshly generated record ID.
+func (w *Writer) writeRecord(recordType string, extra map[string]string, body []byte) error {
+	return w.writeRecordWithID("<urn:uuid:"+newUUID()+">", recordType, extra, body)
+}
+
+func httpHeaderBlock(statusOrRequestLine string, h http.Header) string {
+	s := statusOrRequestLine + "\r\n"
+	for k, vs := range h {
+		for _, v := range vs {
+			s += fmt.Sprintf("%s: %s\r\n", k, v)
+		}
+	}
+	return s + "\r\n"
+}
+
+// WriteExchange records one request/response pair as a request record
+// followed by a response record, linked via WARC-Concurrent-To. truncated
+// indicates the response body was cut off at PathSeeker's read limit; when
+// set, a WARC-Truncated: length header is added to the response record.
+func (w *Writer) WriteExchange(targetURL, method string, reqHeaders http.Header, statusLine string, respHeaders http.Header, body []byte, truncated bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.rotateIfNeeded(); err != nil {
+		return err
+	}
+
+	reqID := "<urn:uuid:" + newUUID() + ">"
+	reqLine := fmt.Sprintf("%s %s HTTP/1.1", method, targetURL)
+	reqBody := []byte(httpHeaderBlock(reqLine, reqHeaders))
+	if err := w.writeRecordWithID(reqID, "request", map[string]string{
+		"WARC-Target-URI": targetURL,
+		"Content-Type":     "application/http; msgtype=request",
+	}, reqBody); err != nil {
+		return err
+	}
+
+	respExtra := map[string]string{
+		"WARC-Target-URI":    targetURL,
+		"WARC-Concurrent-To": reqID,
+		"Content-Type":       "application/http; msgtype=response",
+	}
+	if truncated {
+		respExtra["WARC-Truncated"] = "length"
+	}
+	respBody := append([]byte(httpHeaderBlock(statusLine, respHeaders)), body...)
+	return w.writeRecord("response", respExtra, respBody)
+}
+
+// writeRecordWithID is writeRecord but lets the caller fix the record ID
+// (so the response record can reference it via WARC-Concurrent-To).
+func (w *Writer) writeRecordWithID(id, recordType string, extra map[string]string, body []byte) error {
+	date := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	headers := fmt.Sprintf("WARC/1.0\r\nWARC-Type: %s\r\nWARC-Record-ID: %s\r\nWARC-Date: %s\r\nContent-Length: %d\r\n",
+		recordType, id, date, len(body))
+	for k, v := range extra {
+		headers += fmt.Sprintf("%s: %s\r\n", k, v)
+	}
+	headers += "\r\n"
+
+	n, err := w.gz.Write([]byte(headers))
+	if err != nil {
+		return err
+	}
+	w.writtenSize += int64(n)
+	n, err = w.gz.Write(body)
+	if err != nil {
+		return err
+	}
+	w.writtenSize += int64(n)
+	n, err = w.gz.Write([]byte("\r\n\r\n"))
+	if err != nil {
+		return err
+	}
+	w.writtenSize += int64(n)
+	return w.gz.Flush()
+}
+
+// rotateIfNeeded closes the current file and opens path.N when the current
+// file has grown past maxSize. Caller must hold w.mu.
+func (w *Writer) rotateIfNeeded() error {
+	if w.maxSize <= 0 {
+		return nil
+	}
+	info, err := w.f.Stat()
+	if err != nil || info.Size() < w.maxSize {
+		return nil
+	}
+	w.rotation++
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	return w.openFile(fmt.Sprintf("%s.%d", w.path, w.rotation))
+}
+
+// Close flushes and closes the underlying gzip stream and file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	return w.f.Close()
+}
@@ -1,11 +1,12 @@
 package wordgen
 
 import (
-	"log"
 	"net/url"
 	"path"
 	"sort"
 	"strings"
+
+	"github.com/cybertron10/PathSeeker/internal/logx"
 )
 
 func isUpper(r rune) bool { return r >= 'A' && r <= 'Z' }
@@ -45,10 +46,12 @@ func sanitizeToTokens(s string) []string {
 	return out
 }
 
-// FromURLs extracts unique tokens from URL paths and query keys
-func FromURLs(urls []string, debug bool) []string {
+// FromURLs extracts unique tokens from URL paths and query keys. debug gates
+// the progress logging, emitted through logger so -debug output shares the
+// same structured JSON stream as the rest of a scan's events.
+func FromURLs(urls []string, debug bool, logger *logx.Logger) []string {
 	if debug {
-		log.Printf("DEBUG: Generating wordlist from %d URLs", len(urls))
+		logger.Debugf("Generating wordlist from %d URLs", len(urls))
 	}
 	set := map[string]struct{}{}
 	add := func(w string) { w = strings.ToLower(strings.TrimSpace(w)); if w != "" { set[w] = struct{}{} } }
@@ -63,14 +66,14 @@ func FromURLs(urls []string, debug bool) []string {
 			add(seg)
 			for _, t := range sanitizeToTokens(seg) { add(t) }
 			if debug && seg != "" {
-				log.Printf("DEBUG: Added path segment: %s", seg)
+				logger.Debugf("Added path segment: %s", seg)
 			}
 		}
 		for k := range u.Query() {
 			add(k)
 			for _, t := range sanitizeToTokens(k) { add(t) }
 			if debug && k != "" {
-				log.Printf("DEBUG: Added query parameter: %s", k)
+				logger.Debugf("Added query parameter: %s", k)
 			}
 		}
 		if base := path.Base(u.Path); base != "" && base != "/" {
@@ -79,7 +82,7 @@ func FromURLs(urls []string, debug bool) []string {
 				add(name)
 				for _, t := range sanitizeToTokens(name) { add(t) }
 				if debug && name != "" && name != base {
-					log.Printf("DEBUG: Added base name: %s (from %s)", name, base)
+					logger.Debugf("Added base name: %s (from %s)", name, base)
 				}
 			}
 		}
@@ -88,7 +91,7 @@ func FromURLs(urls []string, debug bool) []string {
 	for w := range set { list = append(list, w) }
 	sort.Strings(list)
 	if debug {
-		log.Printf("DEBUG: Generated %d unique words", len(list))
+		logger.Debugf("Generated %d unique words", len(list))
 	}
 	return list
 }
@@ -0,0 +1,147 @@
+package wordgen
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cybertron10/PathSeeker/internal/logx"
+)
+
+// Source produces a batch of candidate words. Generate aggregates across
+// however many sources a caller wants to combine.
+type Source func() []string
+
+var sourceHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+func fetchBody(u string) ([]byte, error) {
+	resp, err := sourceHTTPClient.Get(u)
+	if err != nil { return nil, err }
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, 4*1024*1024))
+}
+
+// jsIdentifierRe pulls bare identifiers and object keys out of JS source.
+var jsIdentifierRe = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]{2,}`)
+
+// jsStringPathRe matches quoted string literals that look like a URL path.
+var jsStringPathRe = regexp.MustCompile(`["'](/[A-Za-z0-9_\-./]{1,200})["']`)
+
+// jsFetchCallRe matches fetch("/path") / fetch('/path') style calls specifically.
+var jsFetchCallRe = regexp.MustCompile(`(?i)(?:fetch|axios\.(?:get|post|put|delete)|\$\.(?:get|post|ajax))\s*\(\s*["'](/[^"']*)["']`)
+
+// FromJSFiles fetches each referenced .js URL and mines it for identifiers,
+// string literals that look like paths, and API endpoints used in
+// fetch(...)-style calls. It's an AST-lite pass: plain regexes over the
+// source text rather than a real JS parser, which is good enough for the
+// path/identifier shapes we care about.
+func FromJSFiles(jsURLs []string, debug bool, logger *logx.Logger) Source {
+	return func() []string {
+		if debug { logger.Debugf("FromJSFiles mining %d JS files", len(jsURLs)) }
+		var words []string
+		for _, u := range jsURLs {
+			body, err := fetchBody(u)
+			if err != nil { continue }
+			src := string(body)
+			for _, m := range jsIdentifierRe.FindAllString(src, -1) {
+				words = append(words, m)
+			}
+			for _, m := range jsStringPathRe.FindAllStringSubmatch(src, -1) {
+				words = append(words, m[1])
+			}
+			for _, m := range jsFetchCallRe.FindAllStringSubmatch(src, -1) {
+				words = append(words, m[1])
+			}
+		}
+		return words
+	}
+}
+
+// FromSitemap fetches a sitemap XML document, extracts its <loc> URLs, and
+// reuses FromURLs' path/query tokenization over them.
+func FromSitemap(sitemapURL string, debug bool, logger *logx.Logger) Source {
+	return func() []string {
+		body, err := fetchBody(sitemapURL)
+		if err != nil {
+			if debug { logger.Debugf("FromSitemap failed to fetch %s: %v", sitemapURL, err) }
+			return nil
+		}
+		locRe := regexp.MustCompile(`(?is)<loc>\s*([^<\s]+)\s*</loc>`)
+		var urls []string
+		for _, m := range locRe.FindAllStringSubmatch(string(body), -1) {
+			urls = append(urls, strings.TrimSpace(m[1]))
+		}
+		if debug { logger.Debugf("FromSitemap found %d URLs in %s", len(urls), sitemapURL) }
+		return FromURLs(urls, debug, logger)
+	}
+}
+
+// FromWayback queries the Wayback Machine's CDX API for every URL archived
+// under host and reuses FromURLs' tokenization over the results.
+func FromWayback(host string, debug bool, logger *logx.Logger) Source {
+	return func() []string {
+		cdxURL := "https://web.archive.org/cdx/search/cdx?url=" + host + "/*&output=text&fl=original&collapse=urlkey"
+		body, err := fetchBody(cdxURL)
+		if err != nil {
+			if debug { logger.Debugf("FromWayback failed to fetch CDX for %s: %v", host, err) }
+			return nil
+		}
+		lines := strings.Split(string(body), "\n")
+		urls := make([]string, 0, len(lines))
+		for _, l := range lines {
+			l = strings.TrimSpace(l)
+			if l != "" { urls = append(urls, l) }
+		}
+		if debug { logger.Debugf("FromWayback found %d archived URLs for %s", len(urls), host) }
+		return FromURLs(urls, debug, logger)
+	}
+}
+
+// jsonKeyRe matches JSON object keys, e.g. "userId": ...
+var jsonKeyRe = regexp.MustCompile(`"([A-Za-z0-9_\-]{1,64})"\s*:`)
+
+// formFieldNameRe matches HTML form field name="..." attributes.
+var formFieldNameRe = regexp.MustCompile(`(?i)name\s*=\s*["']([A-Za-z0-9_\-\[\]]{1,64})["']`)
+
+// FromResponseBodies extracts JSON object keys and HTML form field names out
+// of a set of previously-fetched response bodies, keyed by the URL they came
+// from (only used for debug logging).
+func FromResponseBodies(bodies map[string][]byte, debug bool, logger *logx.Logger) Source {
+	return func() []string {
+		var words []string
+		for u, body := range bodies {
+			s := string(body)
+			for _, m := range jsonKeyRe.FindAllStringSubmatch(s, -1) {
+				words = append(words, m[1])
+			}
+			for _, m := range formFieldNameRe.FindAllStringSubmatch(s, -1) {
+				words = append(words, m[1])
+			}
+			if debug { logger.Debugf("FromResponseBodies mined %s (%d bytes)", u, len(body)) }
+		}
+		return words
+	}
+}
+
+// Generate runs every source, sanitizes and tokenizes their output the same
+// way FromURLs does, and returns the deduped, sorted union.
+func Generate(sources ...Source) []string {
+	set := map[string]struct{}{}
+	add := func(w string) {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" { set[w] = struct{}{} }
+	}
+	for _, src := range sources {
+		for _, w := range src() {
+			add(w)
+			for _, t := range sanitizeToTokens(w) { add(t) }
+		}
+	}
+	list := make([]string, 0, len(set))
+	for w := range set { list = append(list, w) }
+	sort.Strings(list)
+	return list
+}
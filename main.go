@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"crypto/sha1"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -11,15 +12,20 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/cybertron10/PathSeeker/internal/baseline"
 	"github.com/cybertron10/PathSeeker/internal/crawler"
+	"github.com/cybertron10/PathSeeker/internal/fingerprint"
+	"github.com/cybertron10/PathSeeker/internal/logx"
+	"github.com/cybertron10/PathSeeker/internal/store"
+	"github.com/cybertron10/PathSeeker/internal/warc"
 	"github.com/cybertron10/PathSeeker/internal/wordgen"
-	"log"
 )
 
 // request task represents a single URL attempt and potential recursion
@@ -30,6 +36,32 @@ import (
 	depth    int
 	withSlash bool
 	errorCount int // track consecutive non-200 responses
+	storeSeq *uint64 // set when -state is enabled; lets us store.Complete this task when done
+}
+
+// hitRecord is one -json/-jsonl output line: everything known about a
+// discovered hit at the time it was recorded as the shortest path for its
+// content. ReflectiveParent and DroppedDueToQueue are filled in afterward,
+// if/when this hit's own directory turns out to be reflective or its
+// recursion got skipped because the queue was full.
+type hitRecord struct {
+	URL               string `json:"url"`
+	Status            int    `json:"status"`
+	ContentSHA1       string `json:"content_sha1"`
+	ContentLength     int    `json:"content_length"`
+	Depth             int    `json:"depth"`
+	ParentPrefix      string `json:"parent_prefix"`
+	ElapsedMs         int64  `json:"elapsed_ms"`
+	ReflectiveParent  bool   `json:"reflective_parent"`
+	DroppedDueToQueue bool   `json:"dropped_due_to_queue,omitempty"`
+}
+
+// baselineVerdict is the cached result of probing one directory for a
+// soft-404/reflective baseline: the fingerprint the probes agreed on, and
+// whether they agreed closely enough to call the directory reflective.
+type baselineVerdict struct {
+	fp         baseline.Fingerprint
+	reflective bool
 }
 
 func buildURL(base, prefix, word string, withSlash bool) (string, error) {
@@ -90,6 +122,53 @@ func saveWordsToFile(words []string, outPath string) error {
 	return bw.Flush()
 }
 
+// splitCSV splits a comma-separated flag value, trimming whitespace and
+// dropping empty entries; returns nil for an empty input.
+func splitCSV(s string) []string {
+	if s == "" { return nil }
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" { out = append(out, part) }
+	}
+	return out
+}
+
+// parseScope builds a crawler.Scope from the -scope-* flag values, compiling
+// the include/exclude regexes and parsing the host=N page-cap pairs.
+func parseScope(subdomains bool, allowHosts, denyHosts, includeRegex, excludeRegex, hostPageCap string) crawler.Scope {
+	scope := crawler.Scope{
+		IncludeSubdomains: subdomains,
+		AllowHosts:        splitCSV(allowHosts),
+		DenyHosts:         splitCSV(denyHosts),
+	}
+	for _, pattern := range splitCSV(includeRegex) {
+		if re, err := regexp.Compile(pattern); err == nil {
+			scope.IncludeRegex = append(scope.IncludeRegex, re)
+		} else {
+			fmt.Fprintf(os.Stderr, "scope-include-regex %q: %v\n", pattern, err)
+		}
+	}
+	for _, pattern := range splitCSV(excludeRegex) {
+		if re, err := regexp.Compile(pattern); err == nil {
+			scope.ExcludeRegex = append(scope.ExcludeRegex, re)
+		} else {
+			fmt.Fprintf(os.Stderr, "scope-exclude-regex %q: %v\n", pattern, err)
+		}
+	}
+	if caps := splitCSV(hostPageCap); len(caps) > 0 {
+		scope.MaxPagesPerHost = make(map[string]int, len(caps))
+		for _, pair := range caps {
+			host, nStr, ok := strings.Cut(pair, "=")
+			if !ok { continue }
+			if n, err := strconv.Atoi(strings.TrimSpace(nStr)); err == nil {
+				scope.MaxPagesPerHost[strings.TrimSpace(host)] = n
+			}
+		}
+	}
+	return scope
+}
+
 func parseExcluded(statuses string) map[int]struct{} {
 	set := map[int]struct{}{}
 	if statuses == "" { return set }
@@ -102,6 +181,43 @@ func parseExcluded(statuses string) map[int]struct{} {
 	return set
 }
 
+// describeFingerprint fetches u and formats any matched fingerprint rule
+// names as a " [rule1, rule2]" suffix, or "" on a request error or no match.
+func describeFingerprint(e *fingerprint.Engine, u string) string {
+	resp, err := http.Get(u)
+	if err != nil { return "" }
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256*1024))
+	if err != nil { return "" }
+	results := e.Match(resp, body)
+	if len(results) == 0 { return "" }
+	names := make([]string, 0, len(results))
+	for _, r := range results { names = append(names, r.RuleName) }
+	return " [" + strings.Join(names, ", ") + "]"
+}
+
+// maxWordlistBodyFetches caps how many discovered URLs -wordlist-bodies will
+// fetch to mine for JSON keys/form fields, so a large crawl doesn't turn
+// wordlist generation into a second full scan.
+const maxWordlistBodyFetches = 200
+
+// fetchResponseBodies fetches up to maxBodies of urls for -wordlist-bodies
+// mining; a fetch error just drops that URL, the same best-effort behavior
+// as describeFingerprint.
+func fetchResponseBodies(urls []string, maxBodies int) map[string][]byte {
+	bodies := make(map[string][]byte, maxBodies)
+	for _, u := range urls {
+		if len(bodies) >= maxBodies { break }
+		resp, err := http.Get(u)
+		if err != nil { continue }
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 256*1024))
+		resp.Body.Close()
+		if err != nil { continue }
+		bodies[u] = body
+	}
+	return bodies
+}
+
 func normalizeOutputURL(u string) string {
 	if strings.HasSuffix(u, "/") {
 		if !strings.HasSuffix(u, "://") {
@@ -125,6 +241,34 @@ func main() {
 	var statusExcludeStr string
 	var recursive bool
 	var debug bool
+	var seedRobots bool
+	var seedSitemap bool
+	var seedDisallowEnqueue bool
+	var renderJS bool
+	var renderConcurrency int
+	var fingerprintRules string
+	var crawlUserAgent string
+	var crawlGlobalRPS float64
+	var crawlPerHostRPS float64
+	var crawlHonorCrawlDelay bool
+	var crawlCheckpoint string
+	var warcPath string
+	var warcMaxSizeMB int64
+	var statePath string
+	var baselineProbes int
+	var baselineDistance int
+	var jsonOutput bool
+	var eventsPath string
+	var scopeSubdomains bool
+	var scopeAllowHosts string
+	var scopeDenyHosts string
+	var scopeIncludeRegex string
+	var scopeExcludeRegex string
+	var scopeHostPageCap string
+	var wordlistJS bool
+	var wordlistSitemap bool
+	var wordlistWayback bool
+	var wordlistBodies bool
 
 	flag.StringVar(&base, "u", "", "Base URL, e.g. http://127.0.0.1/")
 	flag.IntVar(&maxDepth, "e", 1, "Error tolerance depth: 1=stop on non-200, 2=allow 1 error level, 3=allow 2 error levels")
@@ -135,6 +279,35 @@ func main() {
 	flag.StringVar(&statusExcludeStr, "se", "404", "Status codes to exclude (comma/space-separated)")
 	flag.BoolVar(&recursive, "r", false, "Enable recursive scanning (continue fuzzing until error tolerance is reached)")
 	flag.BoolVar(&debug, "debug", false, "Enable debug logging")
+	flag.BoolVar(&seedRobots, "seed-robots", false, "Seed the crawl with /robots.txt Disallow entries (its Sitemap entries are used when -seed-sitemap is also set)")
+	flag.BoolVar(&seedSitemap, "seed-sitemap", false, "Seed the crawl with /sitemap.xml entries (including nested indexes and .xml.gz)")
+	flag.BoolVar(&seedDisallowEnqueue, "seed-disallow-enqueue", false, "Enqueue robots.txt Disallow paths for coverage instead of skipping them")
+	flag.BoolVar(&renderJS, "render-js", false, "Render pages in headless Chrome to discover SPA routes and XHR/fetch URLs (falls back to the regex crawler if no browser is available)")
+	flag.IntVar(&renderConcurrency, "render-concurrency", 4, "Max concurrent browser tabs when -render-js is set")
+	flag.StringVar(&fingerprintRules, "fingerprint", "", "Path to a fingerprint YAML ruleset; when set, -crawl-only output is annotated with matched tech-stack rules")
+	flag.StringVar(&crawlUserAgent, "crawl-ua", "", "User-Agent header to use while crawling")
+	flag.Float64Var(&crawlGlobalRPS, "crawl-rps", 0, "Global requests/sec cap for crawling (0 = unlimited)")
+	flag.Float64Var(&crawlPerHostRPS, "crawl-host-rps", 0, "Per-host requests/sec cap for crawling (0 = unlimited)")
+	flag.BoolVar(&crawlHonorCrawlDelay, "crawl-honor-delay", false, "Honor robots.txt Crawl-delay in place of -crawl-host-rps")
+	flag.StringVar(&crawlCheckpoint, "crawl-checkpoint", "", "Path to periodically checkpoint crawl state to, for resuming an interrupted crawl")
+	flag.StringVar(&warcPath, "warc", "", "Record every request/response as WARC records to this gzipped file")
+	flag.Int64Var(&warcMaxSizeMB, "warc-rotate-mb", 500, "Rotate to <path>.N once the current WARC file exceeds this many MB")
+	flag.StringVar(&statePath, "state", "", "Path to a BoltDB state file; persists the task queue so a crashed/interrupted scan can resume")
+	flag.IntVar(&baselineProbes, "baseline-probes", 3, "Number of throwaway-word probes used to fingerprint a directory as reflective/soft-404")
+	flag.IntVar(&baselineDistance, "baseline-distance", 3, "Max SimHash Hamming distance for a response to count as matching a directory's soft-404 baseline")
+	flag.BoolVar(&jsonOutput, "json", false, "Emit one JSON object per hit to stdout instead of plain URLs")
+	flag.BoolVar(&jsonOutput, "jsonl", false, "Alias for -json")
+	flag.StringVar(&eventsPath, "events", "", "Path to write structured JSON scan events (request/reflective_detected/recursion_skipped/queue_full/scan_complete); these are written only when it is set. -debug messages go to this file too, or to stderr without it")
+	flag.BoolVar(&scopeSubdomains, "scope-subdomains", false, "Also crawl *.<base host> subdomains, not just the exact base host")
+	flag.StringVar(&scopeAllowHosts, "scope-allow-hosts", "", "Comma-separated extra hosts (exact or *.example.com glob) to allow alongside the base host")
+	flag.StringVar(&scopeDenyHosts, "scope-deny-hosts", "", "Comma-separated hosts (exact or glob) to exclude even if otherwise in scope")
+	flag.StringVar(&scopeIncludeRegex, "scope-include-regex", "", "Comma-separated regexes; a URL must match at least one to be crawled")
+	flag.StringVar(&scopeExcludeRegex, "scope-exclude-regex", "", "Comma-separated regexes; a URL matching any of these is dropped")
+	flag.StringVar(&scopeHostPageCap, "scope-host-page-cap", "", "Comma-separated host=N page caps, e.g. sub.example.com=50,other.example.com=20")
+	flag.BoolVar(&wordlistJS, "wordlist-js", false, "When auto-generating the wordlist, also mine identifiers/paths out of discovered .js files")
+	flag.BoolVar(&wordlistSitemap, "wordlist-sitemap", false, "When auto-generating the wordlist, also mine tokens from /sitemap.xml")
+	flag.BoolVar(&wordlistWayback, "wordlist-wayback", false, "When auto-generating the wordlist, also mine tokens from the Wayback Machine's archive of the target host")
+	flag.BoolVar(&wordlistBodies, "wordlist-bodies", false, "When auto-generating the wordlist, also mine JSON keys and form field names out of discovered response bodies")
 	flag.CommandLine.Parse(filteredArgs)
 
 	if base == "" {
@@ -147,12 +320,42 @@ func main() {
 	baseURLParsed, _ := url.Parse(base)
 	basePath := baseURLParsed.Path
 
+	crawlOpts := crawler.Options{
+		Debug: debug, SeedRobots: seedRobots, SeedSitemap: seedSitemap, RenderJS: renderJS, RenderConcurrency: renderConcurrency,
+		UserAgent: crawlUserAgent, GlobalRPS: crawlGlobalRPS, PerHostRPS: crawlPerHostRPS, HonorCrawlDelay: crawlHonorCrawlDelay,
+		CheckpointPath: crawlCheckpoint,
+		Scope: parseScope(scopeSubdomains, scopeAllowHosts, scopeDenyHosts, scopeIncludeRegex, scopeExcludeRegex, scopeHostPageCap),
+	}
+	if seedDisallowEnqueue {
+		crawlOpts.Disallow = crawler.DisallowEnqueue
+	}
+	if wordlistJS {
+		crawlOpts.KeepJSLinks = true
+	}
+	doCrawl := crawler.Crawl
+	if renderJS {
+		doCrawl = crawler.CrawlRendered
+	}
+
 	// Crawl-only mode: just crawl and print URLs, then exit
 	if crawlOnly {
 		fmt.Fprintln(os.Stderr, "Crawling domain (depth 10)...")
-		urls, err := crawler.Crawl(base, 10, 20000, debug)
+		urls, err := doCrawl(base, 10, 20000, crawlOpts)
 		if err != nil { fmt.Fprintln(os.Stderr, err); os.Exit(1) }
-		for _, u := range urls { fmt.Println(u) }
+
+		var fpEngine *fingerprint.Engine
+		if fingerprintRules != "" {
+			fpEngine, err = fingerprint.Load(fingerprintRules)
+			if err != nil { fmt.Fprintf(os.Stderr, "fingerprint rules: %v\n", err); os.Exit(1) }
+		}
+
+		for _, u := range urls {
+			if fpEngine == nil {
+				fmt.Println(u)
+				continue
+			}
+			fmt.Println(u + describeFingerprint(fpEngine, u))
+		}
 		fmt.Fprintf(os.Stderr, "Crawled %d URLs\n", len(urls))
 		return
 	}
@@ -173,6 +376,15 @@ func main() {
 		defer fileWriter.Flush()
 	}
 
+	eventsWriter := io.Writer(os.Stderr)
+	if eventsPath != "" {
+		eventsFile, err := os.Create(eventsPath)
+		if err != nil { fmt.Fprintln(os.Stderr, err); os.Exit(1) }
+		defer eventsFile.Close()
+		eventsWriter = eventsFile
+	}
+	logger := logx.New(eventsWriter)
+
 	// Resolve wordlist source: if -w has a value, load it; otherwise crawl and generate (no stdin fallback)
 	var words []string
 	if wordlistPath != "" {
@@ -182,9 +394,33 @@ func main() {
 		words = w
 	} else {
 		fmt.Fprintln(os.Stderr, "Auto-generating wordlist via crawl (depth 10)...")
-		urls, err := crawler.Crawl(base, 10, 2000, debug)
+		urls, err := doCrawl(base, 10, 2000, crawlOpts)
 		if err != nil { fmt.Fprintln(os.Stderr, err); os.Exit(1) }
-		generated := wordgen.FromURLs(urls, debug)
+
+		sources := []wordgen.Source{func() []string { return wordgen.FromURLs(urls, debug, logger) }}
+		if wordlistJS {
+			var jsURLs []string
+			for _, u := range urls {
+				if strings.HasSuffix(strings.ToLower(u), ".js") { jsURLs = append(jsURLs, u) }
+			}
+			fmt.Fprintf(os.Stderr, "Mining %d discovered JS files for wordlist tokens...\n", len(jsURLs))
+			sources = append(sources, wordgen.FromJSFiles(jsURLs, debug, logger))
+		}
+		if wordlistSitemap {
+			sitemapURL := strings.TrimSuffix(base, "/") + "/sitemap.xml"
+			fmt.Fprintln(os.Stderr, "Mining sitemap.xml for wordlist tokens...")
+			sources = append(sources, wordgen.FromSitemap(sitemapURL, debug, logger))
+		}
+		if wordlistWayback {
+			fmt.Fprintf(os.Stderr, "Mining Wayback Machine archives for %s...\n", baseURLParsed.Host)
+			sources = append(sources, wordgen.FromWayback(baseURLParsed.Host, debug, logger))
+		}
+		if wordlistBodies {
+			fmt.Fprintf(os.Stderr, "Mining up to %d discovered response bodies for wordlist tokens...\n", maxWordlistBodyFetches)
+			bodies := fetchResponseBodies(urls, maxWordlistBodyFetches)
+			sources = append(sources, wordgen.FromResponseBodies(bodies, debug, logger))
+		}
+		generated := wordgen.Generate(sources...)
 		fmt.Fprintf(os.Stderr, "Crawl discovered %d URLs; generated %d words\n", len(urls), len(generated))
 		if len(generated) == 0 { fmt.Fprintln(os.Stderr, "auto-generation produced no words"); os.Exit(1) }
 		words = generated
@@ -201,8 +437,8 @@ func main() {
 	fmt.Fprintf(os.Stderr, "Scanning with %d words; mode=%s; error-tolerance=%d; concurrency=%d; exclude=%s\n", len(words), scanMode, maxDepth, concurrency, statusExcludeStr)
 
 	if debug {
-		log.Printf("DEBUG: Configuration - base: %s, maxDepth: %d, concurrency: %d, recursive: %t", base, maxDepth, concurrency, recursive)
-		log.Printf("DEBUG: Wordlist: %d words, excluded statuses: %v", len(words), excluded)
+		logger.Debugf("Configuration - base: %s, maxDepth: %d, concurrency: %d, recursive: %t", base, maxDepth, concurrency, recursive)
+		logger.Debugf("Wordlist: %d words, excluded statuses: %v", len(words), excluded)
 	}
 
 	transport := &http.Transport{
@@ -215,6 +451,14 @@ func main() {
 	}
 	client := &http.Client{ Transport: transport, Timeout: 10 * time.Second }
 
+	var warcWriter *warc.Writer
+	if warcPath != "" {
+		w, err := warc.NewWriter(warcPath, warcMaxSizeMB*1024*1024)
+		if err != nil { fmt.Fprintln(os.Stderr, err); os.Exit(1) }
+		warcWriter = w
+		defer warcWriter.Close()
+	}
+
 	// Memory management: limit queue size to prevent exponential growth
 	maxQueueSize := concurrency * 500 // Allow reasonable depth but prevent explosion
 	reqJobs := make(chan reqTask, maxQueueSize)
@@ -230,38 +474,91 @@ func main() {
 	hashBest := make(map[string]string)
 	hashMu := &sync.Mutex{}
 
+	// -json/-jsonl output: one hitRecord per URL in hashBest, keyed the same way
+	hitRecords := make(map[string]*hitRecord)
+	hitRecordsMu := &sync.Mutex{}
+
+	var taskStore *store.Store
+	var resuming bool
+	if statePath != "" {
+		wordlistHash := sha1.Sum([]byte(strings.Join(words, "\n")))
+		wordlistSHA1 := fmt.Sprintf("%x", wordlistHash)
+		s, err := store.Open(statePath)
+		if err != nil { fmt.Fprintln(os.Stderr, err); os.Exit(1) }
+		taskStore = s
+		defer taskStore.Close()
+		resuming = taskStore.Matches(base, wordlistSHA1)
+		if resuming {
+			if err := taskStore.RequeueInFlight(); err != nil { fmt.Fprintln(os.Stderr, err); os.Exit(1) }
+			if err := taskStore.ClearPendingClaims(); err != nil { fmt.Fprintln(os.Stderr, err); os.Exit(1) }
+			fmt.Fprintln(os.Stderr, "Resuming scan from", statePath)
+		} else {
+			_ = taskStore.SaveMeta("base_url", base)
+			_ = taskStore.SaveMeta("wordlist_sha1", wordlistSHA1)
+		}
+	}
+
 	// track content hashes to detect infinite loops (content that repeats at deeper levels)
 	contentAncestors := make(map[string]map[string]bool) // contentHash -> set of paths where this content was seen
 	contentAncestorsMu := &sync.Mutex{}
 
+	// one soft-404 baseline fingerprint per directory, built lazily the first
+	// time recursion reaches it
+	baselineCache := sync.Map{} // prefix -> baselineVerdict
+
 	// store only 200s for final output (normalized, unique)
 	// final200 := make(map[string]struct{})
 	// finalMu := &sync.Mutex{}
 
-	requestURL := func(fullURL string) (int, string, bool) {
-		if _, loaded := seen.LoadOrStore(fullURL, struct{}{}); loaded { return 0, "", false }
+	requestURL := func(fullURL string) (code int, sum string, contentLength int, elapsedMs int64, ok bool) {
+		start := time.Now()
+		if taskStore != nil {
+			if alreadyClaimed, _ := taskStore.CheckAndMarkSeen(fullURL); alreadyClaimed { return 0, "", 0, 0, false }
+		} else if _, loaded := seen.LoadOrStore(fullURL, struct{}{}); loaded {
+			return 0, "", 0, 0, false
+		}
 		req, err := http.NewRequest(http.MethodGet, fullURL, nil)
-		if err != nil { return 0, "", false }
+		if err != nil { return 0, "", 0, 0, false }
 		req.Header.Set("Connection", "keep-alive")
 		resp, err := client.Do(req)
-		if err != nil { return 0, "", false }
-		code := resp.StatusCode
-		var sum string
-		if code == 200 {
+		if err != nil { return 0, "", 0, 0, false }
+		code = resp.StatusCode
+		if warcWriter != nil {
+			lr := io.LimitReader(resp.Body, 256*1024)
+			body, _ := io.ReadAll(lr)
+			contentLength = len(body)
+			truncated := false
+			if n, _ := resp.Body.Read(make([]byte, 1)); n > 0 { truncated = true }
+			if code == 200 {
+				h := sha1.New()
+				h.Write(body)
+				sum = fmt.Sprintf("%x", h.Sum(nil))
+			}
+			statusLine := fmt.Sprintf("%s %s", resp.Proto, resp.Status)
+			_ = warcWriter.WriteExchange(fullURL, http.MethodGet, req.Header, statusLine, resp.Header, body, truncated)
+		} else if code == 200 {
 			lr := io.LimitReader(resp.Body, 256*1024)
 			h := sha1.New()
-			io.Copy(h, lr)
+			n, _ := io.Copy(h, lr)
+			contentLength = int(n)
 			sum = fmt.Sprintf("%x", h.Sum(nil))
 		}
 		resp.Body.Close()
+		elapsedMs = time.Since(start).Milliseconds()
 		if debug {
-			log.Printf("DEBUG: Request %s -> %d (hash: %s)", fullURL, code, sum)
+			logger.Debugf("Request %s -> %d (hash: %s)", fullURL, code, sum)
+		}
+		if eventsPath != "" {
+			logger.Event("request", map[string]interface{}{"url": fullURL, "status": code, "content_length": contentLength, "elapsed_ms": elapsedMs})
+		}
+		if taskStore != nil {
+			_ = taskStore.MarkSeen(fullURL, code)
 		}
 		if _, skip := excluded[code]; !skip {
 			atomic.AddInt64(&hits, 1)
-			return code, sum, true
+			return code, sum, contentLength, elapsedMs, true
 		}
-		return code, sum, false
+		return code, sum, contentLength, elapsedMs, false
 	}
 
 	// Check if content hash creates an infinite loop
@@ -285,7 +582,7 @@ func main() {
 				// Use "/" suffix to ensure we're checking actual path hierarchy
 				if strings.HasPrefix(currentPath+"/", knownPath+"/") || strings.HasPrefix(knownPath+"/", currentPath+"/") {
 					if debug {
-						log.Printf("DEBUG: Infinite loop detected - content %s already seen at path %s (current: %s)", contentHash, knownPath, currentPath)
+						logger.Debugf("Infinite loop detected - content %s already seen at path %s (current: %s)", contentHash, knownPath, currentPath)
 					}
 					return true
 				}
@@ -307,99 +604,107 @@ func main() {
 		}
 		contentAncestors[contentHash][currentPath] = true
 		if debug {
-			log.Printf("DEBUG: Recorded path %s with content hash %s", currentPath, contentHash)
+			logger.Debugf("Recorded path %s with content hash %s", currentPath, contentHash)
 		}
 	}
 
 	// Pre-check function to detect reflective endpoints at any level
 	preCheckReflective := func(baseURL, prefix string) bool {
+		if cached, ok := baselineCache.Load(prefix); ok {
+			return cached.(baselineVerdict).reflective
+		}
+
 		if debug {
 			checkPath := path.Join(prefix)
 			if checkPath == "" {
 				checkPath = "root"
 			}
-			log.Printf("DEBUG: Pre-checking path '%s' for reflective endpoint", checkPath)
+			logger.Debugf("Pre-checking path '%s' for reflective endpoint", checkPath)
 		}
-		
-		testWords := []string{"test123xyz", "random456abc", "check789def"}
-		type testResult struct {
-			hash   string
-			status int
+
+		testWords := make([]string, baselineProbes)
+		for i := range testWords {
+			testWords[i] = fmt.Sprintf("bltest%dxyz", i)
 		}
-		testResults := make([]testResult, 0, len(testWords))
-		
+		fingerprints := make([]baseline.Fingerprint, 0, len(testWords))
+
 		for _, testWord := range testWords {
 			testURL, err := buildURL(baseURL, prefix, testWord, false)
 			if err != nil {
 				continue
 			}
-			
+
 			testReq, err := http.NewRequest(http.MethodGet, testURL, nil)
 			if err != nil {
 				continue
 			}
-			
+
 			testResp, err := client.Do(testReq)
 			if err != nil {
 				continue
 			}
-			
+
 			status := testResp.StatusCode
-			var testHash string
-			
-			// Hash content for ALL responses (not just 200s)
-			if status != 404 {
-				lr := io.LimitReader(testResp.Body, 256*1024)
-				h := sha1.New()
-				io.Copy(h, lr)
-				testHash = fmt.Sprintf("%x", h.Sum(nil))
+			lr := io.LimitReader(testResp.Body, 256*1024)
+			testBody, _ := io.ReadAll(lr)
+			if warcWriter != nil {
+				truncated := false
+				if n, _ := testResp.Body.Read(make([]byte, 1)); n > 0 { truncated = true }
+				statusLine := fmt.Sprintf("%s %s", testResp.Proto, testResp.Status)
+				_ = warcWriter.WriteExchange(testURL, http.MethodGet, testReq.Header, statusLine, testResp.Header, testBody, truncated)
 			}
 			testResp.Body.Close()
-			
-			testResults = append(testResults, testResult{hash: testHash, status: status})
-			
+
+			fp := baseline.Compute(status, testBody, testResp.Header, testWord)
+			fingerprints = append(fingerprints, fp)
+
 			if debug {
-				log.Printf("DEBUG: Pre-scan %s returned status %d, hash %s", testURL, status, testHash)
+				logger.Debugf("Pre-scan %s returned status %d, length %d, simhash %016x", testURL, status, fp.Length, fp.SimHash)
 			}
 		}
-		
-	// Check if all test words return the same response (status + content)
-	if len(testResults) >= 2 {
-		allSame := true
-		firstResult := testResults[0]
-		
+
 		// Skip pre-check if all are 404s (expected for non-existent paths)
-		if firstResult.status == 404 {
+		all404 := len(fingerprints) >= 2
+		for _, fp := range fingerprints {
+			if fp.Status != 404 { all404 = false; break }
+		}
+		if all404 {
 			if debug {
-				log.Printf("DEBUG: Pre-check skipped - all test paths return 404 (expected behavior)")
+				logger.Debugf("Pre-check skipped - all test paths return 404 (expected behavior)")
 			}
+			baselineCache.Store(prefix, baselineVerdict{fp: fingerprints[0], reflective: false})
 			return false
 		}
-		
-		for _, result := range testResults[1:] {
-			// Compare both status code and content hash
-			if result.status != firstResult.status || result.hash != firstResult.hash {
-				allSame = false
-				break
-			}
-		}
-		
-		if allSame {
-			pathDesc := prefix
-			if pathDesc == "" {
-				pathDesc = "root"
+
+		reflective := false
+		if len(fingerprints) >= 2 {
+			first := fingerprints[0]
+			reflective = true
+			for _, fp := range fingerprints[1:] {
+				if !first.SimilarTo(fp, baselineDistance) {
+					reflective = false
+					break
+				}
 			}
-			fmt.Fprintf(os.Stderr, "\n⚠️  REFLECTIVE ENDPOINT at '%s': All test paths return identical response (status: %d, hash: %s)\n", pathDesc, firstResult.status, firstResult.hash)
-			
-			if debug {
-				log.Printf("DEBUG: Reflective endpoint detected at path '%s' (status %d) - blocking recursion", pathDesc, firstResult.status)
+
+			if reflective {
+				pathDesc := prefix
+				if pathDesc == "" {
+					pathDesc = "root"
+				}
+				fmt.Fprintf(os.Stderr, "\n⚠️  REFLECTIVE ENDPOINT at '%s': All test paths return a near-identical response (status: %d, within Hamming distance %d)\n", pathDesc, first.Status, baselineDistance)
+
+				if debug {
+					logger.Debugf("Reflective endpoint detected at path '%s' (status %d) - blocking recursion", pathDesc, first.Status)
+				}
 			}
-			return true
 		}
+
+		if len(fingerprints) > 0 {
+			baselineCache.Store(prefix, baselineVerdict{fp: fingerprints[0], reflective: reflective})
+		}
+		return reflective
 	}
-	
-	return false
-}
 
 	// Progress bar function
 	updateProgress := func() {
@@ -423,13 +728,16 @@ func main() {
 			func(t reqTask) {
 				defer pending.Done()
 				defer atomic.AddInt64(&completed, 1)
-				
+				if t.storeSeq != nil {
+					defer func() { _ = taskStore.Complete(*t.storeSeq) }()
+				}
+
 				u, err := buildURL(t.base, t.prefix, t.word, t.withSlash)
 				if err != nil { return }
 				if debug {
-					log.Printf("DEBUG: Built URL %s from task %+v", u, t)
+					logger.Debugf("Built URL %s from task %+v", u, t)
 				}
-			code, sum, ok := requestURL(u)
+			code, sum, contentLength, elapsedMs, ok := requestURL(u)
 			if !ok { return }
 			
 			// Record all 200 responses with their content hash for loop detection
@@ -458,6 +766,7 @@ func main() {
 						shouldRecurse := newErrorCount < maxDepth
 						
 						// Apply content-hash pruning for 200s to avoid duplicate content
+						var hr *hitRecord
 						if code == 200 && shouldRecurse {
 							// determine branch key (first segment under base path)
 							norm := normalizeOutputURL(u)
@@ -472,24 +781,40 @@ func main() {
 								}
 							}
 							key := branch + "|" + sum
-							hashMu.Lock()
-							best, exists := hashBest[key]
-							if !exists || len(norm) < len(best) {
-								hashBest[key] = norm
-								best = norm
+							var best string
+							var exists bool
+							if taskStore != nil {
+								best, exists, _ = taskStore.HashBest(key)
+								if !exists || len(norm) < len(best) {
+									_ = taskStore.SetHashBest(key, norm)
+									best = norm
+								}
+							} else {
+								hashMu.Lock()
+								best, exists = hashBest[key]
+								if !exists || len(norm) < len(best) {
+									hashBest[key] = norm
+									best = norm
+								}
+								hashMu.Unlock()
 							}
 							if debug && exists && len(norm) < len(best) {
-								log.Printf("DEBUG: Found shorter path %s (was %s) for hash %s", norm, best, sum)
+								logger.Debugf("Found shorter path %s (was %s) for hash %s", norm, best, sum)
 							}
 							shouldRecurse = (best == norm)
 							if debug && code == 200 {
 								if !shouldRecurse {
-									log.Printf("DEBUG: Skipping recursion for %s (duplicate content)", norm)
+									logger.Debugf("Skipping recursion for %s (duplicate content)", norm)
 								} else {
-									log.Printf("DEBUG: Content %s is unique, proceeding with recursion", norm)
+									logger.Debugf("Content %s is unique, proceeding with recursion", norm)
 								}
 							}
-							hashMu.Unlock()
+							if jsonOutput && shouldRecurse {
+								hr = &hitRecord{URL: norm, Status: code, ContentSHA1: sum, ContentLength: contentLength, Depth: t.depth, ParentPrefix: t.prefix, ElapsedMs: elapsedMs}
+								hitRecordsMu.Lock()
+								hitRecords[key] = hr
+								hitRecordsMu.Unlock()
+							}
 						}
 						
 					// Check for infinite content loops before recursion
@@ -503,13 +828,13 @@ func main() {
 							if checkInfiniteLoop(sum, currentPath) {
 								shouldRecurse = false
 								if debug {
-									log.Printf("DEBUG: Blocked recursion for %s due to infinite loop", u)
+									logger.Debugf("Blocked recursion for %s due to infinite loop", u)
 								}
 							}
 						}
 					}
 						if debug {
-							log.Printf("DEBUG: URL %s -> code %d, errorCount %d, shouldRecurse %t", u, code, newErrorCount, shouldRecurse)
+							logger.Debugf("URL %s -> code %d, errorCount %d, shouldRecurse %t", u, code, newErrorCount, shouldRecurse)
 						}
 
 					if shouldRecurse {
@@ -519,32 +844,62 @@ func main() {
 						if preCheckReflective(t.base, nextPrefix) {
 							// Reflective endpoint detected at this level, skip recursion
 							if debug {
-								log.Printf("DEBUG: Skipping recursion into %s (reflective endpoint)", nextPrefix)
+								logger.Debugf("Skipping recursion into %s (reflective endpoint)", nextPrefix)
+							}
+							if hr != nil {
+								hr.ReflectiveParent = true
+							}
+							if eventsPath != "" {
+								logger.Event("reflective_detected", map[string]interface{}{"prefix": nextPrefix})
+								logger.Event("recursion_skipped", map[string]interface{}{"prefix": nextPrefix, "reason": "reflective"})
 							}
 					} else {
 						// Not reflective, proceed with recursion
 						add := len(words)
-						
+
 						// Memory management: only add tasks if queue has space
 						queueLen := len(reqJobs)
 						availableSpace := maxQueueSize - queueLen
-						
+
 						if availableSpace < add {
 							// Queue is near full, skip this recursion level to prevent memory explosion
 							dropped := add
 							atomic.AddInt64(&droppedTasks, int64(dropped))
 							if debug {
-								log.Printf("DEBUG: Skipping recursion into %s - queue near full (%d/%d tasks, would add %d)", nextPrefix, queueLen, maxQueueSize, add)
+								logger.Debugf("Skipping recursion into %s - queue near full (%d/%d tasks, would add %d)", nextPrefix, queueLen, maxQueueSize, add)
+							}
+							if hr != nil {
+								hr.DroppedDueToQueue = true
+							}
+							if eventsPath != "" {
+								logger.Event("queue_full", map[string]interface{}{"prefix": nextPrefix, "queue_len": queueLen, "max_queue_size": maxQueueSize, "dropped": dropped})
+								logger.Event("recursion_skipped", map[string]interface{}{"prefix": nextPrefix, "reason": "queue_full"})
 							}
 						} else {
 							// Queue has space, add tasks
 							pending.Add(add)
 							atomic.AddInt64(&totalTasks, int64(add))
 							if debug {
-								log.Printf("DEBUG: Recursing into %s with %d new tasks (queue: %d/%d)", nextPrefix, add, queueLen, maxQueueSize)
+								logger.Debugf("Recursing into %s with %d new tasks (queue: %d/%d)", nextPrefix, add, queueLen, maxQueueSize)
 							}
-							for _, w := range words {
-								reqJobs <- reqTask{base: t.base, prefix: nextPrefix, word: w, depth: t.depth + 1, withSlash: false, errorCount: newErrorCount}
+							if taskStore != nil {
+								children := make([]store.Task, len(words))
+								for i, w := range words {
+									children[i] = store.Task{Base: t.base, Prefix: nextPrefix, Word: w, Depth: t.depth + 1, WithSlash: false, ErrorCount: newErrorCount}
+								}
+								seqs, err := taskStore.RecordHitAndEnqueue(u, code, children)
+								if err != nil && debug {
+									logger.Debugf("store.RecordHitAndEnqueue failed for %s: %v", nextPrefix, err)
+								}
+								for i, w := range words {
+									var seq *uint64
+									if i < len(seqs) { s := seqs[i]; seq = &s }
+									reqJobs <- reqTask{base: t.base, prefix: nextPrefix, word: w, depth: t.depth + 1, withSlash: false, errorCount: newErrorCount, storeSeq: seq}
+								}
+							} else {
+								for _, w := range words {
+									reqJobs <- reqTask{base: t.base, prefix: nextPrefix, word: w, depth: t.depth + 1, withSlash: false, errorCount: newErrorCount}
+								}
 							}
 						}
 					}
@@ -564,12 +919,48 @@ func main() {
 		return
 	}
 
-	// seed: all words at root, without trailing slash only
-	seedTasks := len(words)
-	pending.Add(seedTasks)
-	atomic.StoreInt64(&totalTasks, int64(seedTasks))
-	for _, w := range words {
-		reqJobs <- reqTask{base: base, prefix: "", word: w, depth: 0, withSlash: false, errorCount: 0}
+	if resuming {
+		// Resume: drain whatever the previous run left in the persisted queue
+		// instead of reseeding from the wordlist, skipping anything that
+		// actually completed before the crash/interrupt. A task whose URL was
+		// only claimed (CheckAndMarkSeen) but never got a real status - the
+		// worker died mid-request - still needs to fire, so this checks
+		// Completed rather than Seen.
+		var resumedTasks int64
+		for {
+			seq, st, ok, err := taskStore.Dequeue()
+			if err != nil || !ok {
+				break
+			}
+			if u, buildErr := buildURL(st.Base, st.Prefix, st.Word, st.WithSlash); buildErr == nil {
+				if completed, _ := taskStore.Completed(u); completed {
+					_ = taskStore.Complete(seq)
+					continue
+				}
+			}
+			seqCopy := seq
+			resumedTasks++
+			pending.Add(1)
+			reqJobs <- reqTask{base: st.Base, prefix: st.Prefix, word: st.Word, depth: st.Depth, withSlash: st.WithSlash, errorCount: st.ErrorCount, storeSeq: &seqCopy}
+		}
+		atomic.StoreInt64(&totalTasks, resumedTasks)
+		if debug {
+			logger.Debugf("Resumed %d pending task(s) from %s", resumedTasks, statePath)
+		}
+	} else {
+		// seed: all words at root, without trailing slash only
+		seedTasks := len(words)
+		pending.Add(seedTasks)
+		atomic.StoreInt64(&totalTasks, int64(seedTasks))
+		for _, w := range words {
+			var seq *uint64
+			if taskStore != nil {
+				if s, err := taskStore.EnqueueOne(store.Task{Base: base, Prefix: "", Word: w, Depth: 0, WithSlash: false, ErrorCount: 0}); err == nil {
+					seq = &s
+				}
+			}
+			reqJobs <- reqTask{base: base, prefix: "", word: w, depth: 0, withSlash: false, errorCount: 0, storeSeq: seq}
+		}
 	}
 
 	// Start progress updater
@@ -588,24 +979,46 @@ func main() {
 	updateProgress()
 	fmt.Fprintln(os.Stderr) // New line after progress bar
 
-	// emit only 200s at the end based on content hashes (union across branches)
-	hashMu.Lock()
-	seenOut := make(map[string]struct{})
-	for _, u := range hashBest {
-		if _, ok := seenOut[u]; ok { continue }
-		seenOut[u] = struct{}{}
-		writer.WriteString(u)
-		writer.WriteString("\n")
-		if fileWriter != nil { fileWriter.WriteString(u); fileWriter.WriteString("\n") }
+	if jsonOutput {
+		hitRecordsMu.Lock()
+		for _, hr := range hitRecords {
+			data, err := json.Marshal(hr)
+			if err != nil { continue }
+			writer.Write(data)
+			writer.WriteString("\n")
+			if fileWriter != nil { fileWriter.Write(data); fileWriter.WriteString("\n") }
+		}
+		hitRecordsMu.Unlock()
+	} else {
+		// emit only 200s at the end based on content hashes (union across branches)
+		finalHashBest := hashBest
+		if taskStore != nil {
+			if fromStore, err := taskStore.AllHashBest(); err == nil {
+				finalHashBest = fromStore
+			}
+		} else {
+			hashMu.Lock()
+			defer hashMu.Unlock()
+		}
+		seenOut := make(map[string]struct{})
+		for _, u := range finalHashBest {
+			if _, ok := seenOut[u]; ok { continue }
+			seenOut[u] = struct{}{}
+			writer.WriteString(u)
+			writer.WriteString("\n")
+			if fileWriter != nil { fileWriter.WriteString(u); fileWriter.WriteString("\n") }
+		}
 	}
-	hashMu.Unlock()
 
 	fmt.Fprintf(os.Stderr, "Scan complete; %d hits\n", atomic.LoadInt64(&hits))
-	
+
 	// Report dropped tasks if any
 	dropped := atomic.LoadInt64(&droppedTasks)
 	if dropped > 0 {
 		fmt.Fprintf(os.Stderr, "⚠️  Note: %d tasks were dropped due to queue limits (prevents memory explosion)\n", dropped)
 		fmt.Fprintf(os.Stderr, "Tip: Reduce wordlist size or error tolerance (-e) for deeper scans\n")
 	}
+	if eventsPath != "" {
+		logger.Event("scan_complete", map[string]interface{}{"hits": atomic.LoadInt64(&hits), "completed": atomic.LoadInt64(&completed), "dropped_tasks": dropped})
+	}
 }